@@ -2,6 +2,8 @@ package common
 
 const (
 	DefaultLimit = 3
+	// DefaultCountCap is max counted rows of CountCapped strategy
+	DefaultCountCap = 1000
 )
 
 const (
@@ -19,8 +21,54 @@ var DirectionByString map[string]DirectionType = map[string]DirectionType{
 	"desc": DirectionDesc,
 }
 
+const (
+	NullsFirst NullsOrder = "first"
+	NullsLast  NullsOrder = "last"
+)
+
+var NullsByString map[string]NullsOrder = map[string]NullsOrder{
+	"first": NullsFirst,
+	"last":  NullsLast,
+}
+
 const (
 	CursorBasic CursorDirection = iota
 	CursorAfter
 	CursorBefore
 )
+
+const (
+	// LimitClamp reduces limit to max limit
+	LimitClamp LimitPolicy = iota
+	// LimitReject returns ErrLimitExceeded
+	LimitReject
+)
+
+const (
+	// SortAuto detects format: JSON array, AIP-132 if a field has direction or compact
+	SortAuto SortFormat = iota
+	// SortJSON is JSON array: [{"field": "created_at", "direction": "desc"}, {"field": "name"}]
+	SortJSON
+	// SortCompact is comma separated fields, minus is descending order: -created_at,name
+	SortCompact
+	// SortAIP is Google AIP-132 order_by: created_at desc, name
+	SortAIP
+)
+
+const (
+	// ModeCursor is keyset pagination with cursors
+	ModeCursor Mode = iota
+	// ModeOffset is pagination by page numbers with OFFSET
+	ModeOffset
+)
+
+const (
+	// CountExact counts all rows of the query
+	CountExact CountStrategy = iota
+	// CountNone skips counting, TotalRows is -1
+	CountNone
+	// CountCapped counts rows up to the cap, TotalRowsCapped is set if there are more
+	CountCapped
+	// CountEstimated uses row estimate of the PostgreSQL planner
+	CountEstimated
+)
@@ -1,6 +1,10 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrInvalidCursor                    = errors.New("invalid cursor")
@@ -11,5 +15,66 @@ var (
 	ErrInvalidFindDestinationNotSlice   = errors.New("pointer in dst not to slice")
 	ErrEmptyModelInPaginator            = errors.New("paginator.Model is nil")
 	ErrEmptyDBInPaginator               = errors.New("paginator.DB is nil")
-	ErrEmptyGinContextInPaginator       = errors.New("paginator.GinContext is nil")
+	ErrEmptyGinContextInPaginator       = errors.New("paginator.Source and paginator.GinContext are nil")
+	ErrInvalidCursorSignature           = errors.New("invalid cursor signature")
+	ErrInvalidEncryptedCursor           = errors.New("invalid encrypted cursor")
+	ErrInvalidLimit                     = errors.New("invalid limit")
+	ErrLimitExceeded                    = errors.New("limit exceeds max limit")
+	ErrFirstAndLast                     = errors.New("first and last cannot be used together")
+	ErrInvalidFirstOrLast               = errors.New("first and last must be positive")
+	ErrInvalidPage                      = errors.New("invalid page")
+)
+
+// CursorFieldError is returned when a decoded cursor refers to a field or direction that is not allowed
+type CursorFieldError struct {
+	Field     string
+	Direction DirectionType
+}
+
+func (e *CursorFieldError) Error() string {
+	return fmt.Sprintf("invalid cursor field %q with direction %q", e.Field, e.Direction)
+}
+
+// Unwrap makes errors.Is(err, ErrInvalidCursor) true
+func (e *CursorFieldError) Unwrap() error {
+	return ErrInvalidCursor
+}
+
+// SortingFieldError is returned when sorting refers to a field, direction or nulls order outside the sortable fields
+type SortingFieldError struct {
+	Field   string
+	Reason  string
+	Allowed []string
+}
+
+func (e *SortingFieldError) Error() string {
+	return fmt.Sprintf("sorting field %q: %s, allowed fields: %s", e.Field, e.Reason, strings.Join(e.Allowed, ", "))
+}
+
+// Unwrap makes errors.Is(err, ErrInvalidSorting) true
+func (e *SortingFieldError) Unwrap() error {
+	return ErrInvalidSorting
+}
+
+// Names of auxiliary queries in QueryError
+const (
+	QueryTotalCount = "total count"
+	QueryRangeCount = "range count"
+	QueryNextPage   = "next page"
+	QueryPrevPage   = "prev page"
 )
+
+// QueryError is returned when an auxiliary query of the paginator fails
+type QueryError struct {
+	Query string
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("pagination %s query: %v", e.Query, e.Err)
+}
+
+// Unwrap returns error of the query
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
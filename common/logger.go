@@ -0,0 +1,149 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+type (
+	// LogLevel of logger events
+	LogLevel int
+
+	// LogField is structured field of logger event
+	LogField struct {
+		Key   string
+		Value interface{}
+	}
+
+	// Logger receives events of the library. Default logger is silent, see SetLogger and Options.Logger
+	Logger interface {
+		Log(level LogLevel, msg string, fields ...LogField)
+	}
+
+	// SugaredLogger is part of *zap.SugaredLogger used by NewSugaredLogger
+	SugaredLogger interface {
+		Debugw(msg string, keysAndValues ...interface{})
+		Infow(msg string, keysAndValues ...interface{})
+		Warnw(msg string, keysAndValues ...interface{})
+		Errorw(msg string, keysAndValues ...interface{})
+	}
+
+	nopLogger struct{}
+
+	stdLogger struct {
+		l     *log.Logger
+		level LogLevel
+	}
+
+	sugaredLogger struct {
+		l SugaredLogger
+	}
+)
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = nopLogger{}
+)
+
+// SetLogger sets global logger, nil makes it silent
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+
+	logger = l
+}
+
+// GetLogger returns global logger
+func GetLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+
+	return logger
+}
+
+// LoggerOr returns l or global logger if l is nil
+func LoggerOr(l Logger) Logger {
+	if l != nil {
+		return l
+	}
+
+	return GetLogger()
+}
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+func (nopLogger) Log(LogLevel, string, ...LogField) {}
+
+// NewStdLogger returns logger printing events from level to l (log.Default() if nil) as "level msg key=value ..."
+func NewStdLogger(l *log.Logger, level LogLevel) Logger {
+	if l == nil {
+		l = log.New(log.Writer(), log.Prefix(), log.Flags())
+	}
+
+	return &stdLogger{l: l, level: level}
+}
+
+func (s *stdLogger) Log(level LogLevel, msg string, fields ...LogField) {
+	if level < s.level {
+		return
+	}
+
+	var line strings.Builder
+
+	line.WriteString(level.String() + " " + msg)
+
+	for _, f := range fields {
+		fmt.Fprintf(&line, " %s=%v", f.Key, f.Value)
+	}
+
+	s.l.Println(line.String())
+}
+
+// NewSugaredLogger returns logger of zap.SugaredLogger (or other logger with the same methods)
+func NewSugaredLogger(l SugaredLogger) Logger {
+	return &sugaredLogger{l: l}
+}
+
+func (s *sugaredLogger) Log(level LogLevel, msg string, fields ...LogField) {
+	keysAndValues := make([]interface{}, 0, len(fields)*2)
+	for _, f := range fields {
+		keysAndValues = append(keysAndValues, f.Key, f.Value)
+	}
+
+	switch {
+	case level >= LogError:
+		s.l.Errorw(msg, keysAndValues...)
+	case level == LogWarn:
+		s.l.Warnw(msg, keysAndValues...)
+	case level == LogInfo:
+		s.l.Infow(msg, keysAndValues...)
+	default:
+		s.l.Debugw(msg, keysAndValues...)
+	}
+}
@@ -0,0 +1,26 @@
+package common
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	})))
+	l.Log(LogWarn, "invalid cursor", LogField{Key: "error", Value: "bad token"})
+
+	if out := buf.String(); out != `{"level":"WARN","msg":"invalid cursor","error":"bad token"}`+"\n" {
+		t.Errorf("Wrong slog output: %s", out)
+	}
+}
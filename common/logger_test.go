@@ -0,0 +1,56 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+type sugared struct {
+	lines []string
+}
+
+func (s *sugared) Debugw(msg string, kv ...interface{}) { s.add("debug", msg, kv) }
+func (s *sugared) Infow(msg string, kv ...interface{})  { s.add("info", msg, kv) }
+func (s *sugared) Warnw(msg string, kv ...interface{})  { s.add("warn", msg, kv) }
+func (s *sugared) Errorw(msg string, kv ...interface{}) { s.add("error", msg, kv) }
+
+func (s *sugared) add(level, msg string, kv []interface{}) {
+	s.lines = append(s.lines, fmt.Sprint(level, " ", msg, " ", kv))
+}
+
+func TestLogger(t *testing.T) {
+	if _, ok := GetLogger().(nopLogger); !ok {
+		t.Error("default logger must be silent")
+	}
+
+	var buf bytes.Buffer
+
+	SetLogger(NewStdLogger(log.New(&buf, "", 0), LogWarn))
+	GetLogger().Log(LogDebug, "skipped")
+	GetLogger().Log(LogWarn, "invalid cursor", LogField{Key: "error", Value: "bad token"}, LogField{Key: "limit", Value: 3})
+
+	if out := buf.String(); out != "warn invalid cursor error=bad token limit=3\n" {
+		t.Errorf("Wrong std logger output: %q", out)
+	}
+
+	SetLogger(nil)
+	if _, ok := GetLogger().(nopLogger); !ok {
+		t.Error("nil logger must be silent")
+	}
+
+	s := &sugared{}
+	l := NewSugaredLogger(s)
+	l.Log(LogError, "cursor encode failed", LogField{Key: "error", Value: "boom"})
+	l.Log(LogDebug, "sort field not found", LogField{Key: "field", Value: "name"})
+
+	if got := strings.Join(s.lines, "\n"); got != "error cursor encode failed [error boom]\ndebug sort field not found [field name]" {
+		t.Errorf("Wrong sugared logger output: %q", got)
+	}
+
+	if LoggerOr(l) != l {
+		t.Error("LoggerOr must return not nil logger")
+	}
+}
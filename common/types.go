@@ -3,5 +3,20 @@ package common
 // DirectionType ...
 type DirectionType string
 
+// NullsOrder is position of NULL values in sort order: NULLS FIRST / NULLS LAST
+type NullsOrder string
+
 // After/before
 type CursorDirection int
+
+// LimitPolicy defines what to do with limit greater than max limit
+type LimitPolicy int
+
+// Mode of pagination: keyset (cursors) or offset (page numbers)
+type Mode int
+
+// SortFormat is syntax of sorting query
+type SortFormat int
+
+// CountStrategy defines how PageInfo.TotalRows is counted
+type CountStrategy int
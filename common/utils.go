@@ -2,7 +2,6 @@ package common
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
 	"time"
@@ -41,11 +40,7 @@ func SortNameToDBName(sortName string, model interface{}) string {
 }
 
 func columnName(field reflect.StructField) string {
-	tags := field.Tag
-
-	var colName string
-
-	colName = tags.Get("cursor")
+	colName := tagName(field, "cursor")
 	if colName == "" {
 		colName = (&schema.NamingStrategy{}).ColumnName("", field.Name)
 	}
@@ -69,6 +64,22 @@ func (dt DirectionType) Backward(ok bool) DirectionType {
 	}
 }
 
+// Backward of nulls order
+func (n NullsOrder) Backward(ok bool) NullsOrder {
+	if !ok {
+		return n
+	}
+
+	switch n {
+	case NullsFirst:
+		return NullsLast
+	case NullsLast:
+		return NullsFirst
+	default:
+		return n
+	}
+}
+
 func NSortNameToDBName(sortName string, model interface{}) (dbName string) {
 	// modify sortName
 	namesChain := strings.Split(sortName, ".")
@@ -83,30 +94,78 @@ func NSortNameToDBName(sortName string, model interface{}) (dbName string) {
 		dbName += name + "__"
 	}
 
-	dbName = strings.TrimRight(dbName, "_")
-	if strings.Contains(dbName, "__") {
-		return fmt.Sprintf(`"%s"`, dbName)
+	return strings.TrimRight(dbName, "_")
+}
+
+// IsSortableDBName reports whether dbName can be produced by NSortNameToDBName for model.
+// Legacy quoted names of nested fields ("User__name") are accepted too
+func IsSortableDBName(dbName string, model interface{}) bool {
+	if dbName == "" || !reflect.ValueOf(model).IsValid() {
+		return false
 	}
 
-	return dbName
+	if len(dbName) > 1 && strings.HasPrefix(dbName, `"`) && strings.HasSuffix(dbName, `"`) {
+		dbName = dbName[1 : len(dbName)-1]
+	}
+
+	namesChain := strings.Split(dbName, "__")
+
+	typ := reflect.TypeOf(model)
+	for i, n := range namesChain {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		f, ok := searchFieldByDBName(n, typ)
+		if !ok {
+			return false
+		}
+
+		isStruct := f.Type.Kind() == reflect.Struct && getDBName(f) == f.Name
+		if isStruct != (i < len(namesChain)-1) {
+			return false
+		}
+
+		typ = f.Type
+	}
+
+	return true
+}
+
+func searchFieldByDBName(dbName string, typ reflect.Type) (field reflect.StructField, ok bool) {
+	if typ.Kind() != reflect.Struct {
+		return field, false
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		if f.Type.Kind() == reflect.Struct && f.Anonymous {
+			if field, ok := searchFieldByDBName(dbName, f.Type); ok {
+				return field, true
+			}
+
+			continue
+		}
+
+		if getDBName(f) == dbName {
+			return f, true
+		}
+	}
+
+	return field, false
 }
 
 func searchField(name string, model interface{}) (field interface{}, n string) {
 	name = strings.ToLower(name)
 
-	var (
-		typ reflect.Type
-		val reflect.Value
-	)
-
-	if reflect.ValueOf(model).Kind() == reflect.Ptr {
-		typ = reflect.Indirect(reflect.ValueOf(model)).Type()
-		val = reflect.Indirect(reflect.ValueOf(model))
-	} else {
-		typ = reflect.ValueOf(model).Type()
-		val = reflect.ValueOf(model)
+	// nil model and nil pointer have no fields
+	val := reflect.Indirect(reflect.ValueOf(model))
+	if !val.IsValid() {
+		return nil, ""
 	}
 
+	typ := val.Type()
 	if typ.Kind() != reflect.Struct {
 		return nil, ""
 	}
@@ -123,19 +182,20 @@ func searchField(name string, model interface{}) (field interface{}, n string) {
 			}
 		}
 
-		if sName, dbNme := fieldName(f); strings.ToLower(name) == strings.ToLower(sName) {
+		if sName, dbNme := SortName(f); strings.ToLower(name) == strings.ToLower(sName) {
 			return val.Field(i).Interface(), dbNme
 		}
 	}
-	log.Printf("Not found field %s in struct %v\n", name, typ.Name())
+	GetLogger().Log(LogDebug, "sort field not found", LogField{Key: "field", Value: name}, LogField{Key: "struct", Value: typ.Name()})
 
 	return nil, ""
 }
 
-func fieldName(f reflect.StructField) (sortName, dbName string) {
-	if t := f.Tag.Get("cursor"); t != "" {
+// SortName returns public name of the field in sorting (name of cursor or json tag) and its column name
+func SortName(f reflect.StructField) (sortName, dbName string) {
+	if t := tagName(f, "cursor"); t != "" {
 		sortName = t
-	} else if t := f.Tag.Get("json"); t != "" {
+	} else if t := tagName(f, "json"); t != "" {
 		sortName = t
 	} else {
 		sortName = strings.ToLower(f.Name)
@@ -146,6 +206,12 @@ func fieldName(f reflect.StructField) (sortName, dbName string) {
 	return
 }
 
+// tagName returns the name part of tag: `cursor:"name,sortable"` is name
+func tagName(f reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+	return name
+}
+
 func getDBName(f reflect.StructField) (dbName string) {
 	if f.Type.Kind() == reflect.Struct {
 		switch {
@@ -175,3 +241,15 @@ func RevertSlice(dst interface{}) {
 		swap(i, j)
 	}
 }
+
+// TrimSlice cuts slice to n elements, it returns true if the slice was longer
+func TrimSlice(dst interface{}, n int) bool {
+	object := reflect.Indirect(reflect.ValueOf(dst))
+	if object.Len() <= n {
+		return false
+	}
+
+	object.Set(object.Slice(0, n))
+
+	return true
+}
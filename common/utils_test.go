@@ -51,6 +51,8 @@ func TestNSortNameToDBName(t *testing.T) {
 			ItemID      string // Unical +
 			ItemOwnerID int    // Unical
 			ItemType    string `cursor:"item_type_name"`
+			Status      uint   `json:"status,omitempty"`
+			Title       string `cursor:"name,sortable,dir=desc"`
 		}
 	)
 
@@ -60,7 +62,7 @@ func TestNSortNameToDBName(t *testing.T) {
 	}
 
 	testData := []TestDataStruct{
-		{"user.name", `"User__name"`},
+		{"user.name", "User__name"},
 		{"id", "id"},
 		{"comment", "comment"},
 		{"item_type_name", "item_type"},
@@ -68,6 +70,8 @@ func TestNSortNameToDBName(t *testing.T) {
 		{"createdAt", "created_at"},
 		{"DeletedAt", "deleted_at"},
 		{"PublicTime", "public_at"},
+		{"status", "status"},
+		{"name", "title"},
 	}
 
 	for i, td := range testData {
@@ -76,4 +80,59 @@ func TestNSortNameToDBName(t *testing.T) {
 			t.Errorf("%v) Not equal: %s != %s for %s", i, name, td.DBName, td.SortName)
 		}
 	}
+
+	// sort names of the client are not resolved without model
+	for _, model := range []interface{}{nil, (*Material)(nil)} {
+		if name := NSortNameToDBName("comment", model); name != "" {
+			t.Errorf("Wrong name without model: %s", name)
+		}
+	}
+}
+
+func TestIsSortableDBName(t *testing.T) {
+	type (
+		User struct {
+			ID   uint
+			Name string
+		}
+
+		Material struct {
+			ID        uint
+			CreatedAt time.Time `cursor:"createdAt"`
+			ItemType  string    `cursor:"item_type_name"`
+
+			UserID uint
+			User   User
+		}
+	)
+
+	type TestDataStruct struct {
+		DBName   string
+		Sortable bool
+	}
+
+	testData := []TestDataStruct{
+		{"id", true},
+		{"created_at", true},
+		{"item_type", true},
+		{`"User__name"`, true},
+		{`"User__id"`, true},
+		{"User__name", true},
+		{"User", false},
+		{`"User"`, false},
+		{"name", false},
+		{"", false},
+		{"id; DROP TABLE materials", false},
+		{"(select 1)", false},
+	}
+
+	for i, td := range testData {
+		if ok := IsSortableDBName(td.DBName, &Material{}); ok != td.Sortable {
+			t.Errorf("%v) Not equal: %v != %v for %s", i, ok, td.Sortable, td.DBName)
+		}
+	}
+
+	if IsSortableDBName("id", nil) {
+		t.Error("nil model must not have sortable fields")
+	}
 }
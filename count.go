@@ -0,0 +1,75 @@
+package pagination
+
+import (
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+type (
+	// Counter returns total rows of the query for PageInfo.TotalRows
+	Counter func(q CountQuery) (int64, error)
+
+	// CountQuery is the query being paginated: Tx for Find, SQL with Args for FindSQL
+	CountQuery struct {
+		Tx   *gorm.DB
+		SQL  string
+		Args []interface{}
+	}
+
+	// explainPlan is result of EXPLAIN (FORMAT JSON)
+	explainPlan []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+)
+
+var errEmptyExplain = errors.New("empty explain result")
+
+// total counts rows for PageInfo by Options.Counter or Options.CountStrategy, capped reports TotalRowsCapped
+func (p *Paginator) total(e executor) (total int64, capped bool, err error) {
+	switch {
+	case p.options.Counter != nil:
+		total, err = p.options.Counter(e.countQuery())
+	case p.options.CountStrategy == common.CountNone:
+		return -1, false, nil
+	case p.options.CountStrategy == common.CountCapped:
+		limit := int64(p.options.CountCap)
+		if limit == 0 {
+			limit = common.DefaultCountCap
+		}
+
+		total, err = e.countLimit(limit + 1)
+		if total > limit {
+			total, capped = limit, true
+		}
+	case p.options.CountStrategy == common.CountEstimated:
+		total, err = e.estimate()
+	default:
+		total, err = e.count()
+	}
+
+	if err != nil {
+		return -1, false, &common.QueryError{Query: common.QueryTotalCount, Err: err}
+	}
+
+	return total, capped, nil
+}
+
+// parseExplain returns rows estimate of the top plan node
+func parseExplain(raw []byte) (int64, error) {
+	var plan explainPlan
+	if err := json.Unmarshal(raw, &plan); err != nil {
+		return 0, err
+	}
+
+	if len(plan) == 0 {
+		return 0, errEmptyExplain
+	}
+
+	return int64(plan[0].Plan.Rows), nil
+}
@@ -0,0 +1,201 @@
+package cursor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+type (
+	// Codec converts cursor JSON to a token and back
+	Codec interface {
+		Encode(raw []byte) (string, error)
+		Decode(token string) ([]byte, error)
+	}
+
+	// KeyProvider supplies secret keys for cursor codecs
+	KeyProvider interface {
+		// CurrentKey returns the key for new cursors
+		CurrentKey() (id string, key []byte, err error)
+		// Key returns the key by id stored in a cursor
+		Key(id string) (key []byte, err error)
+	}
+
+	// Keyring is a static KeyProvider. Keys other than Current are used only to decode old cursors
+	Keyring struct {
+		Current string
+		Keys    map[string][]byte
+	}
+
+	// Signer is a Codec that appends HMAC-SHA256 signature to the cursor
+	Signer struct {
+		keys KeyProvider
+	}
+
+	// Encrypter is a Codec that encrypts the cursor with AES-GCM, so the token doesn't show field names and values
+	Encrypter struct {
+		keys KeyProvider
+	}
+
+	base64Codec struct{}
+)
+
+var (
+	errUnknownKey = errors.New("unknown cursor key")
+	errInvalidKey = errors.New("invalid cursor key id")
+)
+
+func (base64Codec) Encode(raw []byte) (string, error) {
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (base64Codec) Decode(token string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(token)
+}
+
+// CurrentKey implements KeyProvider
+func (k *Keyring) CurrentKey() (id string, key []byte, err error) {
+	key, err = k.Key(k.Current)
+	return k.Current, key, err
+}
+
+// Key implements KeyProvider
+func (k *Keyring) Key(id string) (key []byte, err error) {
+	key, ok := k.Keys[id]
+	if !ok || len(key) == 0 {
+		return nil, errUnknownKey
+	}
+
+	return key, nil
+}
+
+// NewSigner returns Codec with signed cursors: "<key id>.<payload>.<signature>"
+func NewSigner(keys KeyProvider) *Signer {
+	return &Signer{keys: keys}
+}
+
+// Encode implements Codec
+func (s *Signer) Encode(raw []byte) (string, error) {
+	id, key, err := s.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" || strings.Contains(id, ".") {
+		return "", errInvalidKey
+	}
+
+	signed := id + "." + base64.RawURLEncoding.EncodeToString(raw)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(key, signed)), nil
+}
+
+// Decode implements Codec. Any failure returns common.ErrInvalidCursorSignature
+func (s *Signer) Decode(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, common.ErrInvalidCursorSignature
+	}
+
+	key, err := s.keys.Key(parts[0])
+	if err != nil {
+		return nil, common.ErrInvalidCursorSignature
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, sign(key, parts[0]+"."+parts[1])) {
+		return nil, common.ErrInvalidCursorSignature
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, common.ErrInvalidCursorSignature
+	}
+
+	return raw, nil
+}
+
+// NewEncrypter returns Codec with encrypted cursors: "<key id>.<nonce and ciphertext>".
+// Keys must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256
+func NewEncrypter(keys KeyProvider) *Encrypter {
+	return &Encrypter{keys: keys}
+}
+
+// Encode implements Codec
+func (e *Encrypter) Encode(raw []byte) (string, error) {
+	id, key, err := e.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" || strings.Contains(id, ".") {
+		return "", errInvalidKey
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, raw, []byte(id))
+
+	return id + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decode implements Codec. Any failure returns common.ErrInvalidEncryptedCursor
+func (e *Encrypter) Decode(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, common.ErrInvalidEncryptedCursor
+	}
+
+	key, err := e.keys.Key(parts[0])
+	if err != nil {
+		return nil, common.ErrInvalidEncryptedCursor
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, common.ErrInvalidEncryptedCursor
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, common.ErrInvalidEncryptedCursor
+	}
+
+	raw, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(parts[0]))
+	if err != nil {
+		return nil, common.ErrInvalidEncryptedCursor
+	}
+
+	return raw, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func sign(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
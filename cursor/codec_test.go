@@ -0,0 +1,126 @@
+package cursor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+func TestSigner(t *testing.T) {
+	type Material struct {
+		ID uint
+	}
+
+	oldKeys := &Keyring{Current: "k1", Keys: map[string][]byte{"k1": []byte("secret-1")}}
+	keys := &Keyring{Current: "k2", Keys: map[string][]byte{"k1": []byte("secret-1"), "k2": []byte("secret-2")}}
+
+	decoder := &Decoder{
+		DefaultCursor: New(2, Field{Name: "id", Direction: common.DirectionAsc}),
+		Model:         &Material{},
+		Codec:         NewSigner(keys),
+	}
+
+	c := New(2).AddField("id", 5, common.DirectionAsc)
+
+	c.Codec = NewSigner(keys)
+	token := c.Encode()
+
+	c.Codec = NewSigner(oldKeys)
+	oldToken := c.Encode()
+
+	for i, tkn := range []string{token, oldToken} {
+		cursor, _, err := decoder.Decode(Query{Cursor: tkn})
+		if err != nil {
+			t.Fatalf("%v) Unexpected error: %v", i, err)
+		}
+
+		if len(cursor.Fields) != 1 || cursor.Fields[0].Value != int64(5) {
+			t.Errorf("%v) Wrong cursor: %#v", i, cursor)
+		}
+	}
+
+	c.Limit = 100
+	c.Codec = NewSigner(&Keyring{Current: "k2", Keys: map[string][]byte{"k2": []byte("foreign")}})
+	foreignToken := c.Encode()
+
+	parts := strings.Split(token, ".")
+
+	badTokens := []string{
+		New(100).AddField("id", 0, common.DirectionAsc).Encode(),
+		foreignToken,
+		parts[0] + "." + strings.Split(foreignToken, ".")[1] + "." + parts[2],
+		"k3." + parts[1] + "." + parts[2],
+		parts[0] + "." + parts[1],
+	}
+
+	for i, tkn := range badTokens {
+		for _, q := range []Query{{Cursor: tkn}, {After: tkn}, {Before: tkn}} {
+			if _, _, err := decoder.Decode(q); !errors.Is(err, common.ErrInvalidCursorSignature) {
+				t.Errorf("%v) Expected ErrInvalidCursorSignature, got: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestEncrypter(t *testing.T) {
+	type Material struct {
+		ID      uint
+		Comment string
+	}
+
+	oldKeys := &Keyring{Current: "k1", Keys: map[string][]byte{"k1": []byte("0123456789abcdef")}}
+	keys := &Keyring{Current: "k2", Keys: map[string][]byte{"k1": []byte("0123456789abcdef"), "k2": []byte("0123456789abcdef0123456789abcdef")}}
+
+	decoder := &Decoder{
+		DefaultCursor: New(2, Field{Name: "id", Direction: common.DirectionAsc}),
+		Model:         &Material{},
+		Codec:         NewEncrypter(keys),
+	}
+
+	c := New(2).AddField("comment", "secret@example.com", common.DirectionAsc)
+
+	c.Codec = NewEncrypter(keys)
+	token := c.Encode()
+
+	c.Codec = NewEncrypter(oldKeys)
+	oldToken := c.Encode()
+
+	for i, tkn := range []string{token, oldToken} {
+		if strings.Contains(tkn, "comment") || strings.Contains(tkn, "secret") {
+			t.Errorf("%v) Token is not opaque: %s", i, tkn)
+		}
+
+		cursor, _, err := decoder.Decode(Query{Cursor: tkn})
+		if err != nil {
+			t.Fatalf("%v) Unexpected error: %v", i, err)
+		}
+
+		if len(cursor.Fields) != 1 || cursor.Fields[0].Value != "secret@example.com" {
+			t.Errorf("%v) Wrong cursor: %#v", i, cursor)
+		}
+	}
+
+	if token == c.Encode() || c.Encode() == c.Encode() {
+		t.Error("Tokens must use random nonce")
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := []byte(parts[1])
+	tampered[len(tampered)/2] ^= 1
+
+	badTokens := []string{
+		New(2).AddField("id", 0, common.DirectionAsc).Encode(),
+		parts[0] + "." + string(tampered),
+		"k1." + parts[1],
+		"k3." + parts[1],
+		parts[1],
+	}
+
+	for i, tkn := range badTokens {
+		if _, _, err := decoder.Decode(Query{Cursor: tkn}); !errors.Is(err, common.ErrInvalidEncryptedCursor) {
+			t.Errorf("%v) Expected ErrInvalidEncryptedCursor, got: %v", i, err)
+		}
+	}
+}
@@ -1,10 +1,9 @@
 package cursor
 
 import (
-	"encoding/base64"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
-	"log"
 	"reflect"
 	"regexp"
 	"strings"
@@ -23,14 +22,20 @@ type (
 		Limit    int     `json:"limit"`
 		Backward bool    `json:"backward"`
 
-		DB *gorm.DB `json:"-"`
+		DB     *gorm.DB      `json:"-"`
+		Codec  Codec         `json:"-"`
+		Logger common.Logger `json:"-"`
 	}
 
-	// Field struct
+	// Field struct. Null is true if Value is SQL NULL (Value is nil also for field without value).
+	// NotNull is set by Decoder for NOT NULL columns of the model, they are sorted and compared without NULL handling
 	Field struct {
 		Name      string               `json:"name"`
 		Value     interface{}          `json:"value"`
+		Null      bool                 `json:"null,omitempty"`
 		Direction common.DirectionType `json:"direction"`
+		Nulls     common.NullsOrder    `json:"nulls,omitempty"`
+		NotNull   bool                 `json:"-"`
 	}
 )
 
@@ -42,6 +47,13 @@ func New(limit int, fields ...Field) *Cursor {
 	}
 }
 
+func (c *Cursor) clone() *Cursor {
+	cursor := *c
+	cursor.Fields = append([]Field(nil), c.Fields...)
+
+	return &cursor
+}
+
 func (c *Cursor) SetBackward() *Cursor {
 	if c == nil {
 		return nil
@@ -52,6 +64,21 @@ func (c *Cursor) SetBackward() *Cursor {
 	return c
 }
 
+// Positioned reports whether cursor points to a row (has field values)
+func (c *Cursor) Positioned() bool {
+	if c == nil {
+		return false
+	}
+
+	for _, f := range c.Fields {
+		if f.Value != nil || f.Null {
+			return true
+		}
+	}
+
+	return false
+}
+
 // AddField to cursor
 func (c *Cursor) AddField(name string, value interface{}, order common.DirectionType) *Cursor {
 	if c == nil {
@@ -85,53 +112,124 @@ func (c *Cursor) Scope() func(db *gorm.DB) *gorm.DB {
 
 // where convertation
 func (c *Cursor) where(db *gorm.DB) *gorm.DB {
-	q := db
+	query, val := c.condition(db.Dialector)
+	if query == "" {
+		return db
+	}
 
-	var qList string
+	return db.Where(query, val...)
+}
+
+// condition makes query for rows after the cursor: (a > ?) OR (a = ? AND b > ?) ...
+// Row value form (a, b) > (?, ?) is used if the dialect supports it (see rowCondition)
+func (c *Cursor) condition(q Quoter) (query string, val []interface{}) {
+	if query, val, ok := c.rowCondition(q); ok {
+		return query, val
+	}
+
+	var (
+		groups     []string
+		positioned bool
+	)
 
-	val := make([]interface{}, 0)
-	// Make cursor query
 	for i, f := range c.Fields {
-		if f.Value == nil {
+		if f.Value == nil && !f.Null {
 			continue
 		}
-		query := ""
 
-		for j := 0; j <= i; j++ {
-			if j != i {
-				s := fmt.Sprintf("%v %v ?", c.Fields[j].Name, "=")
-				val = append(val, c.Fields[j].Value)
+		positioned = true
 
-				if j != 0 {
-					query += " AND "
-				}
+		after, afterVal, ok := c.after(q, f)
+		if !ok {
+			continue
+		}
 
-				query += s
-			} else {
-				s := fmt.Sprintf("%v %v ?", c.Fields[j].Name, common.CompareTerms[c.Fields[j].Direction.Backward(c.Backward)])
-				val = append(val, c.Fields[j].Value)
-				if j != 0 {
-					query += " AND "
-				}
-				query += s
+		parts := make([]string, 0, i+1)
+		for _, prev := range c.Fields[:i] {
+			if prev.Null {
+				parts = append(parts, fmt.Sprintf("%v IS NULL", quote(q, prev.Name)))
+				continue
 			}
+
+			parts = append(parts, fmt.Sprintf("%v = ?", quote(q, prev.Name)))
+			val = append(val, prev.Value)
 		}
 
-		qList += fmt.Sprintf(" OR (%v)", query)
+		parts = append(parts, after)
+		val = append(val, afterVal...)
+
+		groups = append(groups, fmt.Sprintf("(%v)", strings.Join(parts, " AND ")))
 	}
 
-	qList = strings.Replace(qList, " OR (", "(", 1)
-	q = q.Where(qList, val...)
+	if positioned && len(groups) == 0 {
+		return "1 = 0", nil
+	}
 
-	//-------
-	return q
+	return strings.Join(groups, " OR "), val
 }
 
-// order convertation
-func (c *Cursor) order(query *gorm.DB) *gorm.DB {
+// rowCondition makes row value comparison (a, b) > (?, ?), it can use composite index (a, b).
+// ok is false for one field, mixed directions or nullable fields, these need the OR form
+func (c *Cursor) rowCondition(q Quoter) (query string, val []interface{}, ok bool) {
+	if len(c.Fields) < 2 || !rowValues(q) {
+		return "", nil, false
+	}
+
+	direction := c.Fields[0].Direction.Backward(c.Backward)
+	names := make([]string, 0, len(c.Fields))
+	marks := make([]string, 0, len(c.Fields))
+
 	for _, f := range c.Fields {
-		order := fmt.Sprintf("%s %s", f.Name, f.Direction.Backward(c.Backward))
+		if !f.NotNull || f.Value == nil || f.Direction.Backward(c.Backward) != direction {
+			return "", nil, false
+		}
+
+		names = append(names, quote(q, f.Name))
+		marks = append(marks, "?")
+		val = append(val, f.Value)
+	}
+
+	query = fmt.Sprintf("(%s) %s (%s)", strings.Join(names, ", "), common.CompareTerms[direction], strings.Join(marks, ", "))
+
+	return query, val, true
+}
 
+// after makes condition for field values after the cursor value, ok is false if there are no such values
+func (c *Cursor) after(q Quoter, f Field) (query string, val []interface{}, ok bool) {
+	name := quote(q, f.Name)
+	nulls := f.nulls().Backward(c.Backward)
+	compare := fmt.Sprintf("%v %v ?", name, common.CompareTerms[f.Direction.Backward(c.Backward)])
+
+	switch {
+	case f.Null && nulls == common.NullsFirst:
+		return fmt.Sprintf("%v IS NOT NULL", name), nil, true
+	case f.Null:
+		return "", nil, false
+	case nulls == common.NullsLast:
+		return fmt.Sprintf("(%v OR %v IS NULL)", compare, name), []interface{}{f.Value}, true
+	default:
+		return compare, []interface{}{f.Value}, true
+	}
+}
+
+// nulls returns order of NULL values of the field: Nulls or PostgreSQL default (NULL is larger than any value),
+// empty for NOT NULL field
+func (f Field) nulls() common.NullsOrder {
+	switch {
+	case f.NotNull:
+		return ""
+	case f.Nulls != "":
+		return f.Nulls
+	case f.Direction == common.DirectionDesc:
+		return common.NullsFirst
+	default:
+		return common.NullsLast
+	}
+}
+
+// order convertation
+func (c *Cursor) order(query *gorm.DB) *gorm.DB {
+	for _, order := range c.orderBy(query.Dialector) {
 		query = query.Order(order)
 		if c.Limit != 0 {
 			query = query.Limit(c.Limit)
@@ -141,40 +239,105 @@ func (c *Cursor) order(query *gorm.DB) *gorm.DB {
 	return query
 }
 
+// orderBy makes ORDER BY expressions of the cursor fields
+func (c *Cursor) orderBy(q Quoter) []string {
+	orders := make([]string, 0, len(c.Fields))
+
+	for _, f := range c.Fields {
+		name := quote(q, f.Name)
+		order := fmt.Sprintf("%s %s", name, f.Direction.Backward(c.Backward))
+
+		switch nulls := f.nulls().Backward(c.Backward); {
+		case nulls == "":
+		case dialectOf(q).nulls:
+			order += fmt.Sprintf(" NULLS %s", strings.ToUpper(string(nulls)))
+		case nulls == common.NullsFirst:
+			// MySQL and SQL Server have no NULLS FIRST/LAST
+			orders = append(orders, fmt.Sprintf("CASE WHEN %s IS NULL THEN 0 ELSE 1 END", name))
+		default:
+			orders = append(orders, fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END", name))
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders
+}
+
 // GroupConditions for GORM v2
 func (c *Cursor) GroupConditions(db *gorm.DB) *gorm.DB {
 	return c.order(c.where(db))
 }
 
-// Encode Cursor to base64 string
+// Encode Cursor to base64 string (or to token of Cursor.Codec)
 func (c *Cursor) Encode() string {
 	raw, err := json.Marshal(c)
 	if err != nil {
-		log.Println("Marshal err:", err)
+		common.LoggerOr(c.Logger).Log(common.LogError, "cursor marshal failed", common.LogField{Key: "error", Value: err})
 		return ""
 	}
 
-	base64str := base64.StdEncoding.EncodeToString(raw)
+	codec := c.Codec
+	if codec == nil {
+		codec = base64Codec{}
+	}
+
+	token, err := codec.Encode(raw)
+	if err != nil {
+		common.LoggerOr(c.Logger).Log(common.LogError, "cursor encode failed", common.LogField{Key: "error", Value: err})
+		return ""
+	}
 
-	return base64str
+	return token
 }
 
 func (c *Cursor) ToCursor(value interface{}) (cursor *Cursor) {
 	cursor = New(c.Limit)
 	cursor.DB = c.DB
+	cursor.Codec = c.Codec
+	cursor.Logger = c.Logger
 
-	for _, f := range c.Fields { // f.Name = `"Author__name"`
+	for _, f := range c.Fields { // f.Name = `Author__name`
 		val := searchFieldValue(f.Name, value)
-		if val != nil {
-			cursor.AddField(f.Name, val, f.Direction)
-		} else {
-			log.Print("!!!")
+		if val == nil {
+			common.LoggerOr(c.Logger).Log(common.LogWarn, "cursor field value not found", common.LogField{Key: "field", Value: f.Name})
+			continue
+		}
+
+		field := Field{
+			Name:      f.Name,
+			Value:     val,
+			Direction: f.Direction,
+			Nulls:     f.Nulls,
+			NotNull:   f.NotNull,
+		}
+
+		if isNull(val) {
+			field.Value = nil
+			field.Null = true
 		}
+
+		cursor.Fields = append(cursor.Fields, field)
 	}
 
 	return
 }
 
+// isNull reports whether value is stored as SQL NULL: nil pointer or driver.Valuer with nil value
+func isNull(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return true
+	}
+
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		return err == nil && v == nil
+	}
+
+	return false
+}
+
 func fieldNameByDBName(f reflect.StructField) string {
 	if f.Anonymous {
 		return f.Name
@@ -203,6 +366,7 @@ func searchFieldValue(name string, in interface{}) (value interface{}) {
 
 	return nil
 }
+
 var (
 	matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 	matchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
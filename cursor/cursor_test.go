@@ -1,10 +1,13 @@
 package cursor
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
 )
 
 func TestFindFieldValueByFieldName(t *testing.T) {
@@ -74,3 +77,59 @@ func TestFindFieldValueByFieldName(t *testing.T) {
 		t.Errorf("%v", v)
 	}
 }
+
+func TestCondition(t *testing.T) {
+	publicAt := func(value interface{}, nulls common.NullsOrder) Field {
+		return Field{Name: "public_at", Value: value, Null: value == nil, Direction: common.DirectionAsc, Nulls: nulls}
+	}
+	desc := func(f Field) Field {
+		f.Direction = common.DirectionDesc
+		return f
+	}
+	id := Field{Name: "id", Value: 3, Direction: common.DirectionAsc, NotNull: true}
+
+	type TestDataStruct struct {
+		Cursor *Cursor
+		Query  string
+		Values int
+	}
+
+	testData := []TestDataStruct{
+		{New(2, Field{Name: "id", Direction: common.DirectionAsc}), "", 0},
+		{New(2, Field{Name: "comment", Value: "A", Direction: common.DirectionAsc, NotNull: true}, id), `("comment", "id") > (?, ?)`, 2},
+		{New(2, Field{Name: "created_at", Value: 1, Direction: common.DirectionAsc, NotNull: true}, id).SetBackward(), `("created_at", "id") < (?, ?)`, 2},
+		{New(2, Field{Name: "comment", Value: "A", Direction: common.DirectionDesc, NotNull: true}, id), `("comment" < ?) OR ("comment" = ? AND "id" > ?)`, 3},
+		{New(2, publicAt(1, common.NullsLast), id), `(("public_at" > ? OR "public_at" IS NULL)) OR ("public_at" = ? AND "id" > ?)`, 3},
+		{New(2, publicAt(1, common.NullsFirst), id), `("public_at" > ?) OR ("public_at" = ? AND "id" > ?)`, 3},
+		{New(2, publicAt(nil, common.NullsLast), id), `("public_at" IS NULL AND "id" > ?)`, 1},
+		{New(2, publicAt(nil, common.NullsFirst), id), `("public_at" IS NOT NULL) OR ("public_at" IS NULL AND "id" > ?)`, 1},
+		{New(2, publicAt(nil, common.NullsLast), id).SetBackward(), `("public_at" IS NOT NULL) OR ("public_at" IS NULL AND "id" < ?)`, 1},
+		{New(2, publicAt(1, common.NullsFirst), id).SetBackward(), `(("public_at" < ? OR "public_at" IS NULL)) OR ("public_at" = ? AND "id" < ?)`, 3},
+		{New(2, publicAt(nil, common.NullsLast)), "1 = 0", 0},
+		// empty nulls order of nullable fields is the default of Postgres: nulls are last in asc and first in desc
+		{New(2, publicAt(1, ""), id), `(("public_at" > ? OR "public_at" IS NULL)) OR ("public_at" = ? AND "id" > ?)`, 3},
+		{New(2, publicAt(nil, ""), id), `("public_at" IS NULL AND "id" > ?)`, 1},
+		{New(2, publicAt(nil, ""), id).SetBackward(), `("public_at" IS NOT NULL) OR ("public_at" IS NULL AND "id" < ?)`, 1},
+		{New(2, desc(publicAt(1, "")), id), `("public_at" < ?) OR ("public_at" = ? AND "id" > ?)`, 3},
+		{New(2, desc(publicAt(nil, "")), id), `("public_at" IS NOT NULL) OR ("public_at" IS NULL AND "id" > ?)`, 1},
+		{New(2, desc(publicAt(nil, "")), id).SetBackward(), `("public_at" IS NULL AND "id" < ?)`, 1},
+		{New(2, desc(publicAt(1, "")), id).SetBackward(), `(("public_at" > ? OR "public_at" IS NULL)) OR ("public_at" = ? AND "id" < ?)`, 3},
+	}
+
+	for i, td := range testData {
+		query, val := td.Cursor.condition(nil)
+		if query != td.Query || len(val) != td.Values {
+			t.Errorf("%v) Not equal:\n%s (%v values)\n%s (%v values)", i, query, len(val), td.Query, td.Values)
+		}
+	}
+
+	// SQL Server has no row value comparison
+	if query, _ := testData[1].Cursor.condition(Brackets); query != "([comment] > ?) OR ([comment] = ? AND [id] > ?)" {
+		t.Errorf("Wrong condition without row values: %s", query)
+	}
+
+	// the same nulls order is in ORDER BY
+	if orderBy := strings.Join(New(2, desc(publicAt(nil, "")), id).orderBy(nil), ", "); orderBy != `"public_at" desc NULLS FIRST, "id" asc` {
+		t.Errorf("Wrong order of nullable field: %s", orderBy)
+	}
+}
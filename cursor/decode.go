@@ -1,50 +1,191 @@
 package cursor
 
 import (
-	"encoding/base64"
 	"encoding/json"
-	"log"
+	"strconv"
+
+	"gorm.io/gorm/schema"
 
 	"github.com/rosberry/go-pagination/common"
 )
 
+type (
+	// Decoder converts request queries to cursors
+	Decoder struct {
+		DefaultCursor *Cursor
+		Model         interface{}
+		Limit         uint
+		MaxLimit      uint
+		LimitPolicy   common.LimitPolicy
+		Codec         Codec
+		Logger        common.Logger
+		// Sortable fields, SortFields of the Model if nil. Any field of the Model is sortable without both
+		Sortable []SortField
+		// SortFormat of Query.Sorting, it is detected by default
+		SortFormat common.SortFormat
+		// Namer is naming strategy of the DB for primary key columns of the Model
+		Namer schema.Namer
+	}
+
+	// Query is raw request values
+	Query struct {
+		Sorting string
+		Cursor  string
+		After   string
+		Before  string
+		Limit   string
+	}
+)
+
 func DecodeAction(sortingQuery, cursorQuery, afterQuery, beforeQuery string, defaultCursor *Cursor, model interface{}, limit uint) (cursor, additionalCursor *Cursor, err error) {
-	if cursorQuery != "" && sortingQuery != "" {
+	d := &Decoder{
+		DefaultCursor: defaultCursor,
+		Model:         model,
+		Limit:         limit,
+	}
+
+	return d.Decode(Query{
+		Sorting: sortingQuery,
+		Cursor:  cursorQuery,
+		After:   afterQuery,
+		Before:  beforeQuery,
+	})
+}
+
+// Decode request query to cursor and additional cursor (before cursor in range request)
+func (d *Decoder) Decode(q Query) (cursor, additionalCursor *Cursor, err error) {
+	cursor, additionalCursor, err = d.decode(q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limit, err := d.requestLimit(q.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, c := range []*Cursor{cursor, additionalCursor} {
+		if c == nil {
+			continue
+		}
+
+		if err := d.applyLimit(c, limit); err != nil {
+			return nil, nil, err
+		}
+
+		c.Codec = d.Codec
+		c.Logger = d.Logger
+		d.markNotNull(c)
+	}
+
+	return cursor, additionalCursor, nil
+}
+
+// markNotNull marks fields of NOT NULL columns of the Model: primary key and columns with not null tag
+func (d *Decoder) markNotNull(c *Cursor) {
+	if d.Model == nil {
+		return
+	}
+
+	s, err := Schema(d.Model, d.Namer)
+	if err != nil {
+		return
+	}
+
+	for i := range c.Fields {
+		if f, ok := s.FieldsByDBName[unquote(c.Fields[i].Name)]; ok && (f.PrimaryKey || f.NotNull) {
+			c.Fields[i].NotNull = true
+		}
+	}
+}
+
+// requestLimit parses limit requested by client, 0 if it is empty
+func (d *Decoder) requestLimit(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 {
+		return 0, common.ErrInvalidLimit
+	}
+
+	return limit, nil
+}
+
+// applyLimit sets requested limit to cursor and enforces max limit on it
+func (d *Decoder) applyLimit(c *Cursor, limit int) error {
+	if limit > 0 {
+		c.Limit = limit
+	}
+
+	if c.Limit <= 0 {
+		c.Limit = common.DefaultLimit
+		if d.Limit > 0 {
+			c.Limit = int(d.Limit)
+		}
+	}
+
+	if d.MaxLimit > 0 && c.Limit > int(d.MaxLimit) {
+		if d.LimitPolicy == common.LimitReject {
+			return common.ErrLimitExceeded
+		}
+
+		c.Limit = int(d.MaxLimit)
+	}
+
+	return nil
+}
+
+func (d *Decoder) decode(q Query) (cursor, additionalCursor *Cursor, err error) {
+	if q.Cursor != "" && q.Sorting != "" {
 		return nil, nil, common.ErrCursorAndSortingTogether
 	}
 
 	defaultCursorFunc := func() *Cursor {
-		cursor = defaultCursor
-		if cursor == nil {
+		if d.DefaultCursor == nil {
 			return nil //, nil, common.ErrInvalidDefaultCursor
 		}
 
-		if limit > 0 {
-			cursor.Limit = int(limit)
+		cursor = d.DefaultCursor.clone()
+		if d.Limit > 0 {
+			cursor.Limit = int(d.Limit)
 		}
 
 		return cursor
 	}
 
 	switch {
-	case cursorQuery != "":
+	case q.Cursor != "":
 		// Work with cursor
 		// Decode string to cursor
-		cursor = decodeCursor(cursorQuery, common.CursorBasic)
+		cursor, err = d.decodeCursor(q.Cursor, common.CursorBasic)
+		if err != nil {
+			return nil, nil, err
+		}
+
 		if cursor == nil {
 			cursor = defaultCursorFunc()
 		}
-	case afterQuery != "" || beforeQuery != "":
+	case q.After != "" || q.Before != "":
 		var afterCursor, beforeCursor *Cursor
-		if afterQuery != "" {
-			afterCursor = decodeCursor(afterQuery, common.CursorAfter)
+		if q.After != "" {
+			afterCursor, err = d.decodeCursor(q.After, common.CursorAfter)
+			if err != nil {
+				return nil, nil, err
+			}
+
 			if afterCursor == nil {
 				cursor = defaultCursorFunc()
 			}
 		}
 
-		if beforeQuery != "" {
-			beforeCursor = decodeCursor(beforeQuery, common.CursorBefore)
+		if q.Before != "" {
+			beforeCursor, err = d.decodeCursor(q.Before, common.CursorBefore)
+			if err != nil {
+				return nil, nil, err
+			}
+
 			if beforeCursor == nil {
 				cursor = defaultCursorFunc()
 			}
@@ -55,21 +196,24 @@ func DecodeAction(sortingQuery, cursorQuery, afterQuery, beforeQuery string, def
 		}
 
 		return afterCursor, beforeCursor, nil
-	case sortingQuery != "":
-		var sort sorting
+	case q.Sorting != "":
+		// sort names are resolved by fields of the model
+		if d.Model == nil {
+			return nil, nil, common.ErrInvalidSorting
+		}
 
-		err := json.Unmarshal([]byte(sortingQuery), &sort)
+		sort, err := parseSorting(q.Sorting, d.SortFormat)
 		if err != nil {
-			return nil, nil, common.ErrInvalidSorting
+			return nil, nil, err
 		}
 
-		cursor = sort.toCursor(model)
-		if cursor == nil {
-			return nil, nil, common.ErrInvalidSorting
+		cursor, err = sort.toCursor(d.Model, d.sortFields(), PrimaryKey(d.Model, d.Namer))
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if limit > 0 {
-			cursor.Limit = int(limit)
+		if d.Limit > 0 {
+			cursor.Limit = int(d.Limit)
 		}
 	default:
 		// Make default cursor
@@ -83,20 +227,51 @@ func DecodeAction(sortingQuery, cursorQuery, afterQuery, beforeQuery string, def
 	return cursor, nil, nil
 }
 
-// decodeCursorString - decode cursor from base64 string
-func decodeCursor(s string, direction common.CursorDirection) *Cursor {
+// decodeCursor decodes and validates client cursor.
+// Broken plain cursor returns nil without error, any Codec failure is returned as error
+func (d *Decoder) decodeCursor(s string, direction common.CursorDirection) (*Cursor, error) {
+	cursor, err := decodeCursor(s, direction, d.Codec, d.Logger)
+	if err != nil {
+		if d.Codec != nil {
+			return nil, err
+		}
+
+		common.LoggerOr(d.Logger).Log(common.LogWarn, "invalid cursor", common.LogField{Key: "error", Value: err})
+		return nil, nil
+	}
+
+	if cursor == nil {
+		return nil, nil
+	}
+
+	if err := d.validate(cursor); err != nil {
+		return nil, err
+	}
+
+	for i := range cursor.Fields {
+		cursor.Fields[i].Name = unquote(cursor.Fields[i].Name)
+	}
+
+	return cursor, nil
+}
+
+// decodeCursorString - decode cursor from token
+func decodeCursor(s string, direction common.CursorDirection, codec Codec, logger common.Logger) (*Cursor, error) {
+	if codec == nil {
+		codec = base64Codec{}
+	}
+
 	var cursor Cursor
 	// Decode
-	raw, err := base64.StdEncoding.DecodeString(s)
+	raw, err := codec.Decode(s)
 	if err != nil {
-		log.Println("Decode err:", err)
-		return nil
+		return nil, err
 	}
 
 	err = json.Unmarshal(raw, &cursor)
 	if err != nil {
-		log.Println("Unmarshal err:", err)
-		return nil
+		common.LoggerOr(logger).Log(common.LogWarn, "invalid cursor", common.LogField{Key: "error", Value: err})
+		return nil, nil
 	}
 
 	switch direction {
@@ -107,5 +282,66 @@ func decodeCursor(s string, direction common.CursorDirection) *Cursor {
 	case common.CursorBasic:
 	}
 
-	return &cursor
+	return &cursor, nil
+}
+
+// sortFields returns the sortable fields, nil if any field of the model is sortable
+func (d *Decoder) sortFields() []SortField {
+	if d.Sortable != nil {
+		return d.Sortable
+	}
+
+	return SortFields(d.Model)
+}
+
+// validate checks decoded cursor fields against the sortable columns of the model and the default cursor
+func (d *Decoder) validate(cursor *Cursor) error {
+	fields := d.sortFields()
+
+	for _, f := range cursor.Fields {
+		if f.Direction != common.DirectionAsc && f.Direction != common.DirectionDesc {
+			return &common.CursorFieldError{Field: f.Name, Direction: f.Direction}
+		}
+
+		if f.Nulls != "" && f.Nulls != common.NullsFirst && f.Nulls != common.NullsLast {
+			return &common.CursorFieldError{Field: f.Name, Direction: f.Direction}
+		}
+
+		if !d.isAllowedField(f, fields) {
+			return &common.CursorFieldError{Field: f.Name, Direction: f.Direction}
+		}
+	}
+
+	return nil
+}
+
+// isAllowedField checks field of the default cursor, sortable field or primary key (tiebreaker of sorting)
+func (d *Decoder) isAllowedField(field Field, fields []SortField) bool {
+	name := unquote(field.Name)
+
+	if d.DefaultCursor != nil {
+		for _, f := range d.DefaultCursor.Fields {
+			if unquote(f.Name) == name {
+				return true
+			}
+		}
+	}
+
+	for _, key := range PrimaryKey(d.Model, d.Namer) {
+		if key == name {
+			return true
+		}
+	}
+
+	if fields == nil {
+		return common.IsSortableDBName(field.Name, d.Model)
+	}
+
+	for i := range fields {
+		if common.NSortNameToDBName(fields[i].Name, d.Model) == name {
+			return fields[i].allows(field.Direction)
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,390 @@
+package cursor
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+func TestDecodeActionValidation(t *testing.T) {
+	type (
+		User struct {
+			ID   uint
+			Name string
+		}
+
+		Material struct {
+			ID      uint
+			Comment string
+			UserID  uint
+			Author  User `gorm:"foreignKey:UserID"`
+		}
+	)
+
+	defaultCursor := New(2, Field{Name: "id", Direction: common.DirectionAsc})
+
+	type TestDataStruct struct {
+		Cursor *Cursor
+		Valid  bool
+	}
+
+	testData := []TestDataStruct{
+		{New(2).AddField("id", 1, common.DirectionAsc), true},
+		{New(2).AddField("comment", "A", common.DirectionDesc).AddField("id", 1, common.DirectionAsc), true},
+		{New(2).AddField(`"Author__name"`, "A", common.DirectionAsc), true},
+		{New(2).AddField("Author__name", "A", common.DirectionAsc), true},
+		{New(2).AddField("id", 1, "asc; DROP TABLE materials"), false},
+		{New(2).AddField("id", 1, ""), false},
+		{New(2).AddField("(select password from users limit 1)", 1, common.DirectionAsc), false},
+		{New(2).AddField("photo", "A", common.DirectionAsc), false},
+	}
+
+	for i, td := range testData {
+		token := td.Cursor.Encode()
+
+		for _, q := range [][3]string{{token, "", ""}, {"", token, ""}, {"", "", token}} {
+			_, _, err := DecodeAction("", q[0], q[1], q[2], defaultCursor, &Material{}, 0)
+
+			if td.Valid && err != nil {
+				t.Errorf("%v) Unexpected error: %v", i, err)
+			}
+
+			var fieldErr *common.CursorFieldError
+			if !td.Valid && (!errors.As(err, &fieldErr) || !errors.Is(err, common.ErrInvalidCursor)) {
+				t.Errorf("%v) Expected CursorFieldError, got: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestDecoderLimit(t *testing.T) {
+	type Material struct {
+		ID      uint
+		Comment string
+	}
+
+	huge := New(100000).AddField("id", 1, common.DirectionAsc).Encode()
+	sortingQuery := `[{"field": "comment", "direction": "asc"}]`
+
+	type TestDataStruct struct {
+		Query  Query
+		Policy common.LimitPolicy
+		Limit  int
+		Err    error
+	}
+
+	testData := []TestDataStruct{
+		{Query{}, common.LimitClamp, 5, nil},
+		{Query{Limit: "7"}, common.LimitClamp, 7, nil},
+		{Query{Limit: "11"}, common.LimitClamp, 10, nil},
+		{Query{Limit: "11"}, common.LimitReject, 0, common.ErrLimitExceeded},
+		{Query{Limit: "0"}, common.LimitClamp, 0, common.ErrInvalidLimit},
+		{Query{Limit: "ten"}, common.LimitClamp, 0, common.ErrInvalidLimit},
+		{Query{Sorting: sortingQuery, Limit: "100"}, common.LimitClamp, 10, nil},
+		{Query{Cursor: huge}, common.LimitClamp, 10, nil},
+		{Query{Cursor: huge}, common.LimitReject, 0, common.ErrLimitExceeded},
+		{Query{Cursor: huge, Limit: "3"}, common.LimitReject, 3, nil},
+		{Query{After: huge}, common.LimitClamp, 10, nil},
+		{Query{Before: huge}, common.LimitClamp, 10, nil},
+		{Query{After: huge, Before: huge}, common.LimitReject, 0, common.ErrLimitExceeded},
+		{Query{Cursor: New(0).AddField("id", 1, common.DirectionAsc).Encode()}, common.LimitClamp, 5, nil},
+	}
+
+	for i, td := range testData {
+		d := &Decoder{
+			DefaultCursor: New(5, Field{Name: "id", Direction: common.DirectionAsc}),
+			Model:         &Material{},
+			Limit:         5,
+			MaxLimit:      10,
+			LimitPolicy:   td.Policy,
+		}
+
+		cursor, additionalCursor, err := d.Decode(td.Query)
+		if err != td.Err {
+			t.Errorf("%v) Not equal error: %v != %v", i, err, td.Err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if cursor.Limit != td.Limit {
+			t.Errorf("%v) Not equal limit: %v != %v", i, cursor.Limit, td.Limit)
+		}
+
+		if additionalCursor != nil && additionalCursor.Limit != td.Limit {
+			t.Errorf("%v) Not equal additional cursor limit: %v != %v", i, additionalCursor.Limit, td.Limit)
+		}
+	}
+}
+
+type logRecorder []string
+
+func (r *logRecorder) Log(level common.LogLevel, msg string, fields ...common.LogField) {
+	*r = append(*r, level.String()+" "+msg)
+}
+
+func TestDecoderLogger(t *testing.T) {
+	var logs logRecorder
+
+	d := &Decoder{DefaultCursor: New(2, Field{Name: "id", Direction: common.DirectionAsc}), Logger: &logs}
+
+	c, _, err := d.Decode(Query{Cursor: "broken token"})
+	if err != nil || c == nil || len(logs) != 1 || logs[0] != "warn invalid cursor" {
+		t.Errorf("Wrong decode of broken cursor: %v %v %v", c, err, logs)
+	}
+
+	if c.Logger != &logs {
+		t.Error("Decoded cursor must use decoder logger")
+	}
+}
+
+func TestSortingPrimaryKey(t *testing.T) {
+	type (
+		Item struct {
+			ID      uint
+			Comment string
+		}
+
+		Document struct {
+			UUID    string `gorm:"primaryKey;column:uuid"`
+			Comment string
+		}
+
+		Country struct {
+			Code    string `gorm:"primaryKey;column:iso_code"`
+			Comment string
+		}
+
+		Clap struct {
+			MaterialID uint `gorm:"primary_key"`
+			ClapperID  uint `gorm:"primary_key"`
+			Comment    string
+		}
+
+		Key struct {
+			Code string `gorm:"primaryKey"`
+		}
+
+		Ticket struct {
+			Key     Key `gorm:"embedded;embeddedPrefix:key_"`
+			Comment string
+		}
+
+		Log struct {
+			Comment string
+		}
+	)
+
+	testData := []struct {
+		Model  interface{}
+		Fields []string
+	}{
+		{&Item{}, []string{"comment", "id"}},
+		{&Document{}, []string{"comment", "uuid"}},
+		{&Country{}, []string{"comment", "iso_code"}},
+		{&Clap{}, []string{"comment", "material_id", "clapper_id"}},
+		{&Ticket{}, []string{"comment", "key_code"}},
+		{&Log{}, []string{"comment"}},
+	}
+
+	for i, td := range testData {
+		d := &Decoder{Model: td.Model}
+
+		c, _, err := d.Decode(Query{Sorting: `[{"field": "comment", "direction": "desc"}]`})
+		if err != nil {
+			t.Fatalf("%v) Unexpected error: %v", i, err)
+		}
+
+		var fields []string
+		for _, f := range c.Fields {
+			fields = append(fields, f.Name)
+		}
+
+		if strings.Join(fields, ",") != strings.Join(td.Fields, ",") {
+			t.Errorf("%v) Wrong cursor fields: %v, expected: %v", i, fields, td.Fields)
+		}
+	}
+}
+
+func TestSortable(t *testing.T) {
+	type (
+		User struct {
+			ID   uint
+			Name string
+		}
+
+		Material struct {
+			ID           uint
+			Comment      string     `cursor:",sortable"`
+			CreatedAt    time.Time  `cursor:"created,sortable,dir=desc,dirs=desc"`
+			PublicAt     *time.Time `json:"PublicTime,omitempty" cursor:",sortable,nulls=first"`
+			PasswordHash string
+			UserID       uint
+			Author       User `gorm:"foreignKey:UserID"`
+		}
+	)
+
+	sortable := []SortField{
+		{Name: "comment"},
+		{Name: "author.name", Direction: common.DirectionDesc},
+	}
+
+	testData := []struct {
+		Sortable []SortField
+		Sorting  string
+		Fields   []Field
+		Err      string
+	}{
+		{nil, `[{"field": "comment"}]`, []Field{{Name: "comment", Direction: common.DirectionAsc}, {Name: "id", Direction: common.DirectionAsc, NotNull: true}}, ""},
+		{nil, `[{"field": "created"}]`, []Field{{Name: "created_at", Direction: common.DirectionDesc}, {Name: "id", Direction: common.DirectionAsc, NotNull: true}}, ""},
+		{nil, `[{"field": "PublicTime", "direction": "desc"}]`, []Field{{Name: "public_at", Direction: common.DirectionDesc, Nulls: common.NullsFirst}, {Name: "id", Direction: common.DirectionAsc, NotNull: true}}, ""},
+		{nil, `[{"field": "PublicTime", "nulls": "last"}]`, []Field{{Name: "public_at", Direction: common.DirectionAsc, Nulls: common.NullsLast}, {Name: "id", Direction: common.DirectionAsc, NotNull: true}}, ""},
+		{nil, `[{"field": "created", "direction": "asc"}]`, nil, `sorting field "created": direction "asc" is not allowed, allowed fields: comment (asc|desc), created (desc), PublicTime (asc|desc)`},
+		{nil, `[{"field": "comment", "nulls": "first"}]`, nil, `sorting field "comment": field is not nullable, allowed fields: comment (asc|desc), created (desc), PublicTime (asc|desc)`},
+		{nil, `[{"field": "passwordhash"}]`, nil, `sorting field "passwordhash": field is not sortable, allowed fields: comment (asc|desc), created (desc), PublicTime (asc|desc)`},
+		{sortable, `[{"field": "author.name"}]`, []Field{{Name: "Author__name", Direction: common.DirectionDesc}, {Name: "id", Direction: common.DirectionAsc, NotNull: true}}, ""},
+		{sortable, `[{"field": "created"}]`, nil, `sorting field "created": field is not sortable, allowed fields: comment (asc|desc), author.name (asc|desc)`},
+	}
+
+	for i, td := range testData {
+		d := &Decoder{Model: &Material{}, Sortable: td.Sortable}
+
+		c, _, err := d.Decode(Query{Sorting: td.Sorting})
+		if td.Err != "" {
+			if err == nil || err.Error() != td.Err || !errors.Is(err, common.ErrInvalidSorting) {
+				t.Errorf("%v) Wrong error: %v", i, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%v) Unexpected error: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.Fields, td.Fields) {
+			t.Errorf("%v) Wrong cursor fields: %+v, expected: %+v", i, c.Fields, td.Fields)
+		}
+	}
+
+	// cursors of the clients are checked too
+	d := &Decoder{Model: &Material{}, DefaultCursor: New(2, Field{Name: "id", Direction: common.DirectionAsc})}
+
+	for token, valid := range map[string]bool{
+		New(2).AddField("created_at", 1, common.DirectionDesc).AddField("id", 1, common.DirectionAsc).Encode(): true,
+		New(2).AddField("created_at", 1, common.DirectionAsc).Encode():                                         false,
+		New(2).AddField("password_hash", "a", common.DirectionAsc).Encode():                                    false,
+	} {
+		if _, _, err := d.Decode(Query{Cursor: token}); (err == nil) != valid {
+			t.Errorf("Wrong cursor validation: %v, expected valid: %v", err, valid)
+		}
+	}
+}
+
+func TestSortFormat(t *testing.T) {
+	type Material struct {
+		ID        uint
+		Name      string
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	expected := []Field{
+		{Name: "created_at", Direction: common.DirectionDesc},
+		{Name: "name", Direction: common.DirectionAsc},
+		{Name: "id", Direction: common.DirectionAsc, NotNull: true},
+	}
+
+	testData := []struct {
+		Format  common.SortFormat
+		Sorting string
+		Valid   bool
+	}{
+		{common.SortAuto, `[{"field": "createdAt", "direction": "desc"}, {"field": "name"}]`, true},
+		{common.SortAuto, "-createdAt,name", true},
+		{common.SortAuto, "-createdAt, +name", true},
+		{common.SortAuto, "createdAt desc, name", true},
+		{common.SortAuto, "createdAt DESC,name asc", true},
+		{common.SortJSON, `[{"field": "createdAt", "direction": "desc"}, {"field": "name"}]`, true},
+		{common.SortCompact, "-createdAt,name", true},
+		{common.SortAIP, "createdAt desc,name", true},
+		{common.SortJSON, "-createdAt,name", false},
+		{common.SortJSON, `[{"field": "createdAt", "direction": "desc", "nulls": "bottom"}, {"field": "name"}]`, false},
+		{common.SortCompact, "createdAt desc,name", false},
+		{common.SortAIP, "createdAt down, name", false},
+		{common.SortAIP, "createdAt desc name", false},
+		{common.SortAuto, "-createdAt,,name", false},
+		{common.SortAuto, "-password", false},
+	}
+
+	for i, td := range testData {
+		d := &Decoder{Model: &Material{}, SortFormat: td.Format}
+
+		c, _, err := d.Decode(Query{Sorting: td.Sorting})
+		if !td.Valid {
+			if !errors.Is(err, common.ErrInvalidSorting) {
+				t.Errorf("%v) Expected ErrInvalidSorting, got: %v", i, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%v) Unexpected error: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.Fields, expected) {
+			t.Errorf("%v) Wrong cursor fields: %+v", i, c.Fields)
+		}
+	}
+
+	if c, _, err := DecodeAction("-createdAt,name", "", "", "", nil, &Material{}, 0); err != nil || !reflect.DeepEqual(c.Fields, expected) {
+		t.Errorf("DecodeAction with compact sorting: %+v %v", c, err)
+	}
+
+	// sorting needs model to resolve the field names
+	if _, _, err := (&Decoder{}).Decode(Query{Sorting: "-name"}); !errors.Is(err, common.ErrInvalidSorting) {
+		t.Errorf("Expected ErrInvalidSorting without model, got: %v", err)
+	}
+}
+
+// upperNamer is naming strategy of the DB with upper case columns
+type upperNamer struct {
+	schema.NamingStrategy
+}
+
+func (n upperNamer) ColumnName(table, column string) string {
+	return strings.ToUpper(n.NamingStrategy.ColumnName(table, column))
+}
+
+func TestPrimaryKeyCursor(t *testing.T) {
+	type (
+		Item struct {
+			Code string `gorm:"column:item_code;primaryKey"`
+			Name string
+		}
+
+		Country struct {
+			Code string `gorm:"primaryKey"`
+		}
+	)
+
+	d := &Decoder{Model: &Item{}, DefaultCursor: New(2, Field{Name: "name", Direction: common.DirectionAsc})}
+
+	// Next cursor of the first page sorted by the default cursor with the key tiebreaker
+	next := New(2).AddField("name", "a", common.DirectionAsc).AddField("item_code", "x", common.DirectionAsc).Encode()
+	if _, _, err := d.Decode(Query{Cursor: next}); err != nil {
+		t.Errorf("Cursor with primary key is not valid: %v", err)
+	}
+
+	if key := PrimaryKey(&Country{}, upperNamer{}); len(key) != 1 || key[0] != "CODE" {
+		t.Errorf("Primary key does not use naming strategy: %v", key)
+	}
+}
@@ -0,0 +1,95 @@
+package cursor
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// Quoter quotes identifiers of SQL dialect, gorm.Dialector implements it
+	Quoter interface {
+		QuoteTo(writer clause.Writer, str string)
+	}
+
+	quoter struct {
+		open, close byte
+		dialect
+	}
+
+	// dialect is SQL syntax supported by database: row values (a, b) > (?, ?), NULLS FIRST/LAST in ORDER BY
+	// and OFFSET ... FETCH NEXT ... instead of LIMIT
+	dialect struct {
+		rows, nulls, fetch bool
+	}
+)
+
+var (
+	// DoubleQuote quotes identifiers as "name" (PostgreSQL, SQLite, ANSI SQL)
+	DoubleQuote Quoter = quoter{'"', '"', dialect{rows: true, nulls: true}}
+	// Backtick quotes identifiers as `name` (MySQL, SQLite), NULLS FIRST/LAST are emulated as in MySQL
+	Backtick Quoter = quoter{'`', '`', dialect{rows: true}}
+	// Brackets quotes identifiers as [name] (SQL Server)
+	Brackets Quoter = quoter{'[', ']', dialect{fetch: true}}
+)
+
+// QuoteTo writes quoted identifier, parts of "table.column" are quoted separately
+func (q quoter) QuoteTo(writer clause.Writer, str string) {
+	for i, part := range strings.Split(str, ".") {
+		if i > 0 {
+			writer.WriteByte('.')
+		}
+
+		writer.WriteByte(q.open)
+		writer.WriteString(strings.ReplaceAll(part, string(q.close), string(q.close)+string(q.close)))
+		writer.WriteByte(q.close)
+	}
+}
+
+// quote field name with quoter, DoubleQuote is used if quoter is nil
+func quote(q Quoter, name string) string {
+	if q == nil {
+		q = DoubleQuote
+	}
+
+	var sql strings.Builder
+	q.QuoteTo(&sql, unquote(name))
+
+	return sql.String()
+}
+
+// dialectOf returns syntax of the quoter dialect. Nil quoter is DoubleQuote, gorm dialectors are checked by name,
+// other quoters use LIMIT and NULLS FIRST/LAST without row values
+func dialectOf(q Quoter) dialect {
+	switch q := q.(type) {
+	case nil:
+		return DoubleQuote.(quoter).dialect
+	case quoter:
+		return q.dialect
+	case interface{ Name() string }:
+		switch q.Name() {
+		case "postgres", "sqlite":
+			return dialect{rows: true, nulls: true}
+		case "mysql":
+			return dialect{rows: true}
+		case "sqlserver":
+			return dialect{fetch: true}
+		}
+	}
+
+	return dialect{nulls: true}
+}
+
+// rowValues reports whether dialect of the quoter compares row values: (a, b) > (?, ?)
+func rowValues(q Quoter) bool {
+	return dialectOf(q).rows
+}
+
+// unquote returns name without double quotes, legacy cursors store nested names quoted: "Author__name"
+func unquote(name string) string {
+	if len(name) > 1 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		return name[1 : len(name)-1]
+	}
+
+	return name
+}
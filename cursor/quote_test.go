@@ -0,0 +1,113 @@
+package cursor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+func TestQuote(t *testing.T) {
+	c := New(2,
+		Field{Name: "Author__name", Value: "A", Direction: common.DirectionAsc, NotNull: true},
+		Field{Name: `"order"`, Value: 3, Direction: common.DirectionDesc, NotNull: true},
+	)
+
+	type TestDataStruct struct {
+		Dialect string
+		Quoter  Quoter
+		Query   string
+		OrderBy string
+	}
+
+	testData := []TestDataStruct{
+		{"default", nil, `("Author__name" > ?) OR ("Author__name" = ? AND "order" < ?)`, `"Author__name" asc, "order" desc`},
+		{"postgres", postgres.Dialector{}, `("Author__name" > ?) OR ("Author__name" = ? AND "order" < ?)`, `"Author__name" asc, "order" desc`},
+		{"sqlite", sqlite.Dialector{}, "(`Author__name` > ?) OR (`Author__name` = ? AND `order` < ?)", "`Author__name` asc, `order` desc"},
+		{"mysql", mysql.Dialector{}, "(`Author__name` > ?) OR (`Author__name` = ? AND `order` < ?)", "`Author__name` asc, `order` desc"},
+		{"double quote", DoubleQuote, `("Author__name" > ?) OR ("Author__name" = ? AND "order" < ?)`, `"Author__name" asc, "order" desc`},
+		{"backtick", Backtick, "(`Author__name` > ?) OR (`Author__name` = ? AND `order` < ?)", "`Author__name` asc, `order` desc"},
+		{"sqlserver", Brackets, "([Author__name] > ?) OR ([Author__name] = ? AND [order] < ?)", "[Author__name] asc, [order] desc"},
+	}
+
+	for _, td := range testData {
+		query, _ := c.condition(td.Quoter)
+		orderBy := strings.Join(c.orderBy(td.Quoter), ", ")
+
+		if query != td.Query || orderBy != td.OrderBy {
+			t.Errorf("%s) Not equal:\n%s ORDER BY %s\n%s ORDER BY %s", td.Dialect, query, orderBy, td.Query, td.OrderBy)
+		}
+	}
+
+	if q := quote(Brackets, "t.a]b"); q != "[t].[a]]b]" {
+		t.Errorf("Wrong quoting: %s", q)
+	}
+}
+
+func TestScopeQuote(t *testing.T) {
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	// nullable field is ordered by NULLS LAST, MySQL has CASE expression instead
+	c := New(2,
+		Field{Name: "Author__name", Value: "A", Direction: common.DirectionAsc},
+		Field{Name: "id", Value: 3, Direction: common.DirectionAsc, NotNull: true},
+	)
+
+	testData := map[string]gorm.Dialector{
+		`SELECT * FROM "t" WHERE (("Author__name" > $1 OR "Author__name" IS NULL)) OR ("Author__name" = $2 AND "id" > $3) ` +
+			`ORDER BY "Author__name" asc NULLS LAST,"id" asc LIMIT 2`: postgres.New(postgres.Config{Conn: sqlDB}),
+		"SELECT * FROM `t` WHERE ((`Author__name` > ? OR `Author__name` IS NULL)) OR (`Author__name` = ? AND `id` > ?) " +
+			"ORDER BY CASE WHEN `Author__name` IS NULL THEN 1 ELSE 0 END,`Author__name` asc,`id` asc LIMIT 2": mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		"SELECT * FROM `t` WHERE ((`Author__name` > ? OR `Author__name` IS NULL)) OR (`Author__name` = ? AND `id` > ?) " +
+			"ORDER BY `Author__name` asc NULLS LAST,`id` asc LIMIT 2": sqlite.Open(":memory:"),
+	}
+
+	for expected, dialector := range testData {
+		db, err := gorm.Open(dialector, &gorm.Config{DryRun: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var rows []map[string]interface{}
+		if sql := db.Table("t").Scopes(c.Scope()).Find(&rows).Statement.SQL.String(); sql != expected {
+			t.Errorf("%s) Not equal:\n%s\n%s", dialector.Name(), sql, expected)
+		}
+	}
+}
+
+func TestNullsOrder(t *testing.T) {
+	publicAt := Field{Name: "public_at", Direction: common.DirectionDesc}
+	id := Field{Name: "id", Direction: common.DirectionAsc, NotNull: true}
+
+	type TestDataStruct struct {
+		Dialect string
+		Quoter  Quoter
+		Cursor  *Cursor
+		OrderBy string
+	}
+
+	testData := []TestDataStruct{
+		{"postgres", postgres.Dialector{}, New(2, publicAt, id), `"public_at" desc NULLS FIRST, "id" asc`},
+		{"postgres", postgres.Dialector{}, New(2, publicAt, id).SetBackward(), `"public_at" asc NULLS LAST, "id" desc`},
+		{"sqlite", sqlite.Dialector{}, New(2, publicAt, id), "`public_at` desc NULLS FIRST, `id` asc"},
+		{"mysql", mysql.Dialector{}, New(2, publicAt, id), "CASE WHEN `public_at` IS NULL THEN 0 ELSE 1 END, `public_at` desc, `id` asc"},
+		{"mysql", Backtick, New(2, publicAt, id).SetBackward(), "CASE WHEN `public_at` IS NULL THEN 1 ELSE 0 END, `public_at` asc, `id` desc"},
+		{"sqlserver", Brackets, New(2, publicAt, id), "CASE WHEN [public_at] IS NULL THEN 0 ELSE 1 END, [public_at] desc, [id] asc"},
+	}
+
+	for _, td := range testData {
+		if orderBy := strings.Join(td.Cursor.orderBy(td.Quoter), ", "); orderBy != td.OrderBy {
+			t.Errorf("%s) Not equal:\n%s\n%s", td.Dialect, orderBy, td.OrderBy)
+		}
+	}
+}
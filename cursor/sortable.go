@@ -0,0 +1,174 @@
+package cursor
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+// SortField is rule of sortable field. Name is public name in sorting, nested fields are joined by dot: author.name.
+// Empty Directions allow both directions, Direction is used if sorting has no direction (asc if empty).
+// Only Nullable fields accept nulls order, Nulls is their order by default (last if empty)
+type SortField struct {
+	Name       string
+	Directions []common.DirectionType
+	Direction  common.DirectionType
+	Nullable   bool
+	Nulls      common.NullsOrder
+}
+
+// SortFields reads sortable fields from cursor tags of the model: `cursor:"name,sortable,dir=desc"`.
+// Tag options: sortable, dir=asc|desc (default direction), dirs=asc|desc (allowed directions), nullable, nulls=first|last.
+// It returns nil if the model has no sortable fields
+func SortFields(model interface{}) []SortField {
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return nil
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return tagSortFields(typ)
+}
+
+func tagSortFields(typ reflect.Type) (fields []SortField) {
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Type.Kind() == reflect.Struct && f.Anonymous {
+			fields = append(fields, tagSortFields(f.Type)...)
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("cursor"), ",")
+
+		field := SortField{Name: tag[0]}
+		sortable := false
+
+		for _, option := range tag[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+			switch key {
+			case "sortable":
+				sortable = true
+			case "dir":
+				field.Direction = common.DirectionType(value)
+			case "dirs":
+				for _, d := range strings.Split(value, "|") {
+					field.Directions = append(field.Directions, common.DirectionType(d))
+				}
+			case "nullable":
+				field.Nullable = true
+			case "nulls":
+				field.Nullable, field.Nulls = true, common.NullsOrder(value)
+			}
+		}
+
+		if !sortable {
+			continue
+		}
+
+		if field.Name == "" {
+			field.Name, _ = common.SortName(f)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// sortOrder returns direction and nulls order of the sorting element if fields allow them
+func sortOrder(fields []SortField, e sortingElem) (direction common.DirectionType, nulls common.NullsOrder, err error) {
+	rule := sortRule(fields, e.Field)
+	if rule == nil {
+		return "", "", sortFieldError(fields, e.Field, "field is not sortable")
+	}
+
+	direction = common.DirectionAsc
+	if rule.Direction != "" {
+		direction = rule.Direction
+	}
+
+	if e.Direction != "" {
+		direction = common.DirectionType(strings.ToLower(e.Direction))
+	}
+
+	if !rule.allows(direction) {
+		return "", "", sortFieldError(fields, e.Field, "direction "+strconv.Quote(string(direction))+" is not allowed")
+	}
+
+	if !rule.Nullable {
+		if e.Nulls != "" {
+			return "", "", sortFieldError(fields, e.Field, "field is not nullable")
+		}
+
+		return direction, "", nil
+	}
+
+	nulls = common.NullsLast
+	if rule.Nulls != "" {
+		nulls = rule.Nulls
+	}
+
+	if e.Nulls != "" {
+		var ok bool
+		if nulls, ok = common.NullsByString[strings.ToLower(e.Nulls)]; !ok {
+			return "", "", sortFieldError(fields, e.Field, "nulls order "+strconv.Quote(e.Nulls)+" is not allowed")
+		}
+	}
+
+	return direction, nulls, nil
+}
+
+// sortRule returns rule of the sorting field, nil if fields do not contain it
+func sortRule(fields []SortField, name string) *SortField {
+	for i := range fields {
+		if strings.EqualFold(fields[i].Name, name) {
+			return &fields[i]
+		}
+	}
+
+	return nil
+}
+
+// allows reports whether the rule allows direction
+func (f *SortField) allows(direction common.DirectionType) bool {
+	if len(f.Directions) == 0 {
+		return direction == common.DirectionAsc || direction == common.DirectionDesc
+	}
+
+	for _, d := range f.Directions {
+		if d == direction {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortFieldError makes error listing the allowed fields with their directions: name (asc|desc)
+func sortFieldError(fields []SortField, name, reason string) error {
+	allowed := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		directions := []string{string(common.DirectionAsc), string(common.DirectionDesc)}
+		if len(f.Directions) > 0 {
+			directions = directions[:0]
+			for _, d := range f.Directions {
+				directions = append(directions, string(d))
+			}
+		}
+
+		allowed = append(allowed, f.Name+" ("+strings.Join(directions, "|")+")")
+	}
+
+	return &common.SortingFieldError{Field: name, Reason: reason, Allowed: allowed}
+}
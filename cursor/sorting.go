@@ -1,24 +1,32 @@
 package cursor
 
 import (
-	"reflect"
+	"encoding/json"
 	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
 
 	"github.com/rosberry/go-pagination/common"
 )
 
+// schemas is cache of parsed models
+var schemas sync.Map
+
 type (
 	sortingElem struct {
 		Field     string `json:"field" form:"field"`
 		Direction string `json:"direction" form:"direction"`
+		Nulls     string `json:"nulls" form:"nulls"`
 	}
 
 	sorting []sortingElem
 )
 
-func (srt *sorting) toCursor(model interface{}) *Cursor {
+// toCursor makes cursor of the sorting with primary key tiebreaker, nil fields allow any field of the model (see Decoder.Sortable)
+func (srt *sorting) toCursor(model interface{}, fields []SortField, primaryKey []string) (*Cursor, error) {
 	if srt == nil {
-		return nil
+		return nil, common.ErrInvalidSorting
 	}
 
 	cursor := &Cursor{
@@ -32,40 +40,141 @@ func (srt *sorting) toCursor(model interface{}) *Cursor {
 			direction = common.DirectionAsc
 		}
 
+		nulls, ok := common.NullsByString[strings.ToLower(e.Nulls)]
+		if !ok && e.Nulls != "" {
+			return nil, common.ErrInvalidSorting
+		}
+
+		if fields != nil {
+			var err error
+			if direction, nulls, err = sortOrder(fields, e); err != nil {
+				return nil, err
+			}
+		}
+
 		fieldName := common.NSortNameToDBName(e.Field, model)
 		if fieldName == "" {
-			return nil
+			return nil, common.ErrInvalidSorting
 		}
 
 		cursor.AddField(fieldName, nil, direction)
+		cursor.Fields[len(cursor.Fields)-1].Nulls = nulls
+	}
+
+	// primary key is tiebreaker of non-unique sort fields
+	for _, name := range primaryKey {
+		if !cursor.hasField(name) {
+			cursor.AddField(name, nil, common.DirectionAsc)
+		}
 	}
 
-	// check and add id field
-	var idExist bool
+	return cursor, nil
+}
 
-	for _, f := range cursor.Fields {
-		if f.Name == "id" {
-			idExist = true
-			break
+// Schema parses the model with naming strategy of the DB (schema.NamingStrategy if namer is nil).
+// Parsed models are cached by type, a model is parsed once with the first namer
+func Schema(model interface{}, namer schema.Namer) (*schema.Schema, error) {
+	if namer == nil {
+		namer = schema.NamingStrategy{}
+	}
+
+	return schema.Parse(model, &schemas, namer)
+}
+
+// PrimaryKey returns column names of the model primary key from GORM schema (composite key has several),
+// nil if the model has no primary key or cannot be parsed
+func PrimaryKey(model interface{}, namer schema.Namer) []string {
+	if model == nil {
+		return nil
+	}
+
+	s, err := Schema(model, namer)
+	if err != nil {
+		return nil
+	}
+
+	return s.PrimaryFieldDBNames
+}
+
+func (c *Cursor) hasField(name string) bool {
+	for _, f := range c.Fields {
+		if f.Name == name {
+			return true
 		}
 	}
 
-	if !idExist {
-		var typ reflect.Type
-		if reflect.ValueOf(model).Kind() == reflect.Ptr {
-			typ = reflect.Indirect(reflect.ValueOf(model)).Type()
-		} else {
-			typ = reflect.ValueOf(model).Type()
+	return false
+}
+
+// parseSorting parses sorting query of the format
+func parseSorting(s string, format common.SortFormat) (sorting, error) {
+	if format == common.SortAuto {
+		format = detectSortFormat(s)
+	}
+
+	var srt sorting
+
+	switch format {
+	case common.SortJSON:
+		if err := json.Unmarshal([]byte(s), &srt); err != nil {
+			return nil, common.ErrInvalidSorting
 		}
 
-		for i := 0; i < typ.NumField(); i++ {
-			structField := typ.Field(i)
-			if strings.ToLower(structField.Name) == "id" {
-				cursor.AddField("id", nil, common.DirectionAsc)
-				break
+		return srt, nil
+	case common.SortCompact, common.SortAIP:
+	default:
+		return nil, common.ErrInvalidSorting
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		var e sortingElem
+
+		if format == common.SortCompact {
+			e.Field = strings.TrimSpace(part)
+
+			switch {
+			case strings.HasPrefix(e.Field, "-"):
+				e.Field, e.Direction = e.Field[1:], string(common.DirectionDesc)
+			case strings.HasPrefix(e.Field, "+"):
+				e.Field, e.Direction = e.Field[1:], string(common.DirectionAsc)
+			}
+		} else {
+			words := strings.Fields(part)
+			if len(words) == 0 || len(words) > 2 {
+				return nil, common.ErrInvalidSorting
+			}
+
+			e.Field = words[0]
+			if len(words) == 2 {
+				if _, ok := common.DirectionByString[strings.ToLower(words[1])]; !ok {
+					return nil, common.ErrInvalidSorting
+				}
+
+				e.Direction = words[1]
 			}
 		}
+
+		if e.Field == "" || strings.ContainsAny(e.Field, " \t") {
+			return nil, common.ErrInvalidSorting
+		}
+
+		srt = append(srt, e)
+	}
+
+	return srt, nil
+}
+
+// detectSortFormat returns SortJSON for JSON array, SortAIP if a field has direction after space or SortCompact
+func detectSortFormat(s string) common.SortFormat {
+	if strings.HasPrefix(strings.TrimSpace(s), "[") {
+		return common.SortJSON
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		if len(strings.Fields(part)) > 1 {
+			return common.SortAIP
+		}
 	}
 
-	return cursor
+	return common.SortCompact
 }
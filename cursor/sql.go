@@ -0,0 +1,145 @@
+package cursor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Placeholder is bind variable style of database/sql driver
+	Placeholder int
+
+	// SQLBuilder renders cursors to database/sql queries. Nil Quoter quotes identifiers with DoubleQuote
+	SQLBuilder struct {
+		Placeholder Placeholder
+		Quoter      Quoter
+	}
+
+	// SQL is cursor rendered to query parts
+	SQL struct {
+		Where   string
+		OrderBy string
+		Limit   int
+		Args    []interface{}
+	}
+)
+
+const (
+	// Question placeholder: ? (MySQL, SQLite)
+	Question Placeholder = iota
+	// Dollar placeholder: $1, $2 ... (PostgreSQL)
+	Dollar
+)
+
+// Build renders cursor. Dollar placeholders are numbered after argIndex (the number of args used before)
+func (b SQLBuilder) Build(c *Cursor, argIndex int) SQL {
+	where, args := c.condition(b.Quoter)
+
+	return SQL{
+		Where:   b.placeholders(where, argIndex),
+		OrderBy: strings.Join(c.orderBy(b.Quoter), ", "),
+		Limit:   c.Limit,
+		Args:    args,
+	}
+}
+
+// Select wraps query: SELECT * FROM (query) AS t WHERE ... ORDER BY ... LIMIT ...
+// Conditions of all cursors are joined, order and limit are taken from the first cursor
+func (b SQLBuilder) Select(query string, args []interface{}, cursors ...*Cursor) (string, []interface{}) {
+	return b.SelectOffset(query, args, 0, cursors...)
+}
+
+// SelectOffset is Select skipping offset rows. Limit and offset are rendered for dialect of the Quoter:
+// LIMIT n OFFSET m, or ORDER BY ... OFFSET m ROWS FETCH NEXT n ROWS ONLY for SQL Server
+func (b SQLBuilder) SelectOffset(query string, args []interface{}, offset int, cursors ...*Cursor) (string, []interface{}) {
+	var (
+		sql     strings.Builder
+		where   []string
+		orderBy string
+		limit   int
+	)
+
+	args = append([]interface{}(nil), args...)
+
+	for _, c := range cursors {
+		part := b.Build(c, len(args))
+		if part.Where != "" {
+			where = append(where, "("+part.Where+")")
+			args = append(args, part.Args...)
+		}
+	}
+
+	fmt.Fprintf(&sql, "SELECT * FROM (%s) AS t", query)
+
+	if len(where) > 0 {
+		sql.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
+
+	if len(cursors) > 0 {
+		orderBy, limit = strings.Join(cursors[0].orderBy(b.Quoter), ", "), cursors[0].Limit
+	}
+
+	if orderBy != "" {
+		sql.WriteString(" ORDER BY " + orderBy)
+	}
+
+	sql.WriteString(b.limit(orderBy != "", limit, offset))
+
+	return sql.String(), args
+}
+
+// limit renders LIMIT and OFFSET. SQL Server needs ORDER BY for OFFSET FETCH, and OFFSET for ORDER BY in subqueries
+func (b SQLBuilder) limit(ordered bool, limit, offset int) string {
+	var sql strings.Builder
+
+	if !dialectOf(b.Quoter).fetch {
+		if limit > 0 {
+			sql.WriteString(" LIMIT " + strconv.Itoa(limit))
+		}
+
+		if offset > 0 {
+			sql.WriteString(" OFFSET " + strconv.Itoa(offset))
+		}
+
+		return sql.String()
+	}
+
+	if !ordered {
+		if limit == 0 && offset == 0 {
+			return ""
+		}
+
+		sql.WriteString(" ORDER BY (SELECT NULL)")
+	}
+
+	sql.WriteString(" OFFSET " + strconv.Itoa(offset) + " ROWS")
+
+	if limit > 0 {
+		sql.WriteString(" FETCH NEXT " + strconv.Itoa(limit) + " ROWS ONLY")
+	}
+
+	return sql.String()
+}
+
+// placeholders replaces ? in the cursor condition with the placeholder style
+func (b SQLBuilder) placeholders(query string, argIndex int) string {
+	if b.Placeholder != Dollar {
+		return query
+	}
+
+	var sql strings.Builder
+
+	for _, r := range query {
+		if r == '?' {
+			argIndex++
+			sql.WriteString("$" + strconv.Itoa(argIndex))
+
+			continue
+		}
+
+		sql.WriteRune(r)
+	}
+
+	return sql.String()
+}
@@ -0,0 +1,88 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+func TestSQLBuilder(t *testing.T) {
+	after := New(2, Field{Name: "comment", Value: "A", Direction: common.DirectionAsc, NotNull: true}, Field{Name: "id", Value: 3, Direction: common.DirectionDesc, NotNull: true})
+	before := New(2, Field{Name: "comment", Value: "C", Direction: common.DirectionAsc, NotNull: true}, Field{Name: "id", Value: 1, Direction: common.DirectionDesc, NotNull: true}).SetBackward()
+
+	type TestDataStruct struct {
+		Builder SQLBuilder
+		Cursors []*Cursor
+		Query   string
+		Args    int
+	}
+
+	testData := []TestDataStruct{
+		{
+			SQLBuilder{Placeholder: Dollar},
+			[]*Cursor{New(2, Field{Name: "id", Direction: common.DirectionAsc, NotNull: true})},
+			`SELECT * FROM (SELECT * FROM materials WHERE user_id = $1) AS t ORDER BY "id" asc LIMIT 2`,
+			1,
+		},
+		{
+			SQLBuilder{Placeholder: Dollar},
+			[]*Cursor{after, before},
+			`SELECT * FROM (SELECT * FROM materials WHERE user_id = $1) AS t ` +
+				`WHERE (("comment" > $2) OR ("comment" = $3 AND "id" < $4)) AND (("comment" < $5) OR ("comment" = $6 AND "id" > $7)) ` +
+				`ORDER BY "comment" asc, "id" desc LIMIT 2`,
+			7,
+		},
+		{
+			SQLBuilder{Placeholder: Question, Quoter: Backtick},
+			[]*Cursor{before},
+			"SELECT * FROM (SELECT * FROM materials WHERE user_id = $1) AS t " +
+				"WHERE ((`comment` < ?) OR (`comment` = ? AND `id` > ?)) ORDER BY `comment` desc, `id` asc LIMIT 2",
+			4,
+		},
+		{
+			SQLBuilder{Placeholder: Question, Quoter: Brackets},
+			[]*Cursor{after},
+			"SELECT * FROM (SELECT * FROM materials WHERE user_id = $1) AS t " +
+				"WHERE (([comment] > ?) OR ([comment] = ? AND [id] < ?)) ORDER BY [comment] asc, [id] desc OFFSET 0 ROWS FETCH NEXT 2 ROWS ONLY",
+			4,
+		},
+		{
+			SQLBuilder{Placeholder: Question, Quoter: Brackets},
+			[]*Cursor{New(5)},
+			"SELECT * FROM (SELECT * FROM materials WHERE user_id = $1) AS t ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY",
+			1,
+		},
+		{
+			SQLBuilder{Placeholder: Question, Quoter: Brackets},
+			nil,
+			"SELECT * FROM (SELECT * FROM materials WHERE user_id = $1) AS t",
+			1,
+		},
+	}
+
+	for i, td := range testData {
+		query, args := td.Builder.Select("SELECT * FROM materials WHERE user_id = $1", []interface{}{5}, td.Cursors...)
+		if query != td.Query || len(args) != td.Args {
+			t.Errorf("%v) Not equal:\n%s (%v args)\n%s (%v args)", i, query, len(args), td.Query, td.Args)
+		}
+	}
+
+	part := SQLBuilder{Placeholder: Dollar}.Build(after, 3)
+	if part.Where != `("comment" > $4) OR ("comment" = $5 AND "id" < $6)` || part.OrderBy != `"comment" asc, "id" desc` || part.Limit != 2 || len(part.Args) != 3 {
+		t.Errorf("Wrong SQL: %#v", part)
+	}
+
+	// offset mode
+	offset := map[Quoter]string{
+		nil:      `SELECT * FROM (SELECT * FROM materials) AS t ORDER BY "comment" asc, "id" desc LIMIT 2 OFFSET 4`,
+		Backtick: "SELECT * FROM (SELECT * FROM materials) AS t ORDER BY `comment` asc, `id` desc LIMIT 2 OFFSET 4",
+		Brackets: "SELECT * FROM (SELECT * FROM materials) AS t ORDER BY [comment] asc, [id] desc OFFSET 4 ROWS FETCH NEXT 2 ROWS ONLY",
+	}
+
+	for quoter, expected := range offset {
+		page := New(2, Field{Name: "comment", Direction: common.DirectionAsc, NotNull: true}, Field{Name: "id", Direction: common.DirectionDesc, NotNull: true})
+		if query, _ := (SQLBuilder{Quoter: quoter}).SelectOffset("SELECT * FROM materials", nil, 4, page); query != expected {
+			t.Errorf("Not equal:\n%s\n%s", query, expected)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package cursor
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+type (
+	// ValueCodec converts cursor values of one type to JSON and back
+	ValueCodec interface {
+		Encode(v interface{}) (json.RawMessage, error)
+		Decode(raw json.RawMessage) (interface{}, error)
+	}
+
+	jsonValueCodec struct {
+		typ reflect.Type
+	}
+
+	// fieldJSON is Field with value type, so value can be restored without precision loss
+	fieldJSON struct {
+		Name      string               `json:"name"`
+		Value     json.RawMessage      `json:"value"`
+		Type      string               `json:"type,omitempty"`
+		Null      bool                 `json:"null,omitempty"`
+		Direction common.DirectionType `json:"direction"`
+		Nulls     common.NullsOrder    `json:"nulls,omitempty"`
+	}
+)
+
+const (
+	valueInt    = "int"
+	valueFloat  = "float"
+	valueString = "string"
+	valueBool   = "bool"
+	valueTime   = "time"
+	valueBytes  = "bytes"
+)
+
+var (
+	valuesMu     sync.RWMutex
+	valueCodecs  = map[string]ValueCodec{}
+	valueTypes   = map[reflect.Type]string{}
+	builtinKinds = map[string]bool{
+		valueInt: true, valueFloat: true, valueString: true, valueBool: true, valueTime: true, valueBytes: true,
+	}
+
+	errUnknownValueType = errors.New("unknown cursor value type")
+)
+
+// RegisterValue registers codec for cursor values of the same type as sample.
+// kind is stored in the cursor to find the codec on decode. Nil codec uses encoding/json of the type, e.g.:
+//
+//	cursor.RegisterValue("uuid", uuid.UUID{}, nil)
+func RegisterValue(kind string, sample interface{}, codec ValueCodec) {
+	if kind == "" || builtinKinds[kind] {
+		panic(fmt.Sprintf("cursor: invalid value kind %q", kind))
+	}
+
+	typ := reflect.TypeOf(sample)
+	if codec == nil {
+		codec = jsonValueCodec{typ: typ}
+	}
+
+	valuesMu.Lock()
+	defer valuesMu.Unlock()
+
+	valueCodecs[kind] = codec
+	valueTypes[typ] = kind
+}
+
+func (c jsonValueCodec) Encode(v interface{}) (json.RawMessage, error) {
+	return json.Marshal(v)
+}
+
+func (c jsonValueCodec) Decode(raw json.RawMessage) (interface{}, error) {
+	v := reflect.New(c.typ)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, err
+	}
+
+	return v.Elem().Interface(), nil
+}
+
+// MarshalJSON stores field value with its type
+func (f Field) MarshalJSON() ([]byte, error) {
+	kind, raw, err := encodeValue(f.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fieldJSON{
+		Name:      f.Name,
+		Value:     raw,
+		Type:      kind,
+		Null:      f.Null,
+		Direction: f.Direction,
+		Nulls:     f.Nulls,
+	})
+}
+
+// UnmarshalJSON restores field value by its type. Fields without type are decoded as plain JSON
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var fj fieldJSON
+	if err := json.Unmarshal(data, &fj); err != nil {
+		return err
+	}
+
+	value, err := decodeValue(fj.Type, fj.Value)
+	if err != nil {
+		return err
+	}
+
+	*f = Field{
+		Name:      fj.Name,
+		Value:     value,
+		Null:      fj.Null,
+		Direction: fj.Direction,
+		Nulls:     fj.Nulls,
+	}
+
+	return nil
+}
+
+func encodeValue(v interface{}) (kind string, raw json.RawMessage, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", json.RawMessage("null"), nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return "", json.RawMessage("null"), nil
+	}
+
+	v = rv.Interface()
+
+	valuesMu.RLock()
+	kind, ok := valueTypes[rv.Type()]
+	codec := valueCodecs[kind]
+	valuesMu.RUnlock()
+
+	if ok {
+		raw, err = codec.Encode(v)
+		return kind, raw, err
+	}
+
+	if t, ok := v.(time.Time); ok {
+		raw, err = json.Marshal(t.Format(time.RFC3339Nano))
+		return valueTime, raw, err
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return "", nil, err
+		}
+
+		return encodeValue(dv)
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		raw, err = json.Marshal(strconv.FormatInt(rv.Int(), 10))
+		return valueInt, raw, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		raw, err = json.Marshal(strconv.FormatUint(rv.Uint(), 10))
+		return valueInt, raw, err
+	case reflect.Float32, reflect.Float64:
+		raw, err = json.Marshal(rv.Float())
+		return valueFloat, raw, err
+	case reflect.String:
+		raw, err = json.Marshal(rv.String())
+		return valueString, raw, err
+	case reflect.Bool:
+		raw, err = json.Marshal(rv.Bool())
+		return valueBool, raw, err
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			raw, err = json.Marshal(rv.Bytes())
+			return valueBytes, raw, err
+		}
+	}
+
+	raw, err = json.Marshal(v)
+
+	return "", raw, err
+}
+
+func decodeValue(kind string, raw json.RawMessage) (value interface{}, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	switch kind {
+	case "":
+		err = json.Unmarshal(raw, &value)
+		return value, err
+	case valueInt:
+		var s json.Number
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+
+		if i, err := strconv.ParseInt(s.String(), 10, 64); err == nil {
+			return i, nil
+		}
+
+		return strconv.ParseUint(s.String(), 10, 64)
+	case valueFloat:
+		var f float64
+		err = json.Unmarshal(raw, &f)
+		return f, err
+	case valueString:
+		var s string
+		err = json.Unmarshal(raw, &s)
+		return s, err
+	case valueBool:
+		var b bool
+		err = json.Unmarshal(raw, &b)
+		return b, err
+	case valueTime:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+
+		return time.Parse(time.RFC3339Nano, s)
+	case valueBytes:
+		var b []byte
+		err = json.Unmarshal(raw, &b)
+		return b, err
+	}
+
+	valuesMu.RLock()
+	codec, ok := valueCodecs[kind]
+	valuesMu.RUnlock()
+
+	if !ok {
+		return nil, errUnknownValueType
+	}
+
+	return codec.Decode(raw)
+}
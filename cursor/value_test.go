@@ -0,0 +1,72 @@
+package cursor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+type testUUID [4]byte
+
+func TestFieldValue(t *testing.T) {
+	RegisterValue("test_uuid", testUUID{}, nil)
+
+	publicAt := time.Date(2020, 12, 31, 23, 59, 59, 123456789, time.FixedZone("", 3*60*60))
+	var nilTime *time.Time
+
+	type TestDataStruct struct {
+		Value    interface{}
+		Expected interface{}
+	}
+
+	testData := []TestDataStruct{
+		{int64(math.MaxInt64), int64(math.MaxInt64)},
+		{int64(1<<53 + 1), int64(1<<53 + 1)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{uint(7), int64(7)},
+		{1.5, 1.5},
+		{"A", "A"},
+		{true, true},
+		{publicAt, publicAt},
+		{&publicAt, publicAt},
+		{nilTime, nil},
+		{nil, nil},
+		{testUUID{1, 2, 3, 4}, testUUID{1, 2, 3, 4}},
+		{sql.NullString{String: "B", Valid: true}, "B"},
+		{sql.NullInt64{}, nil},
+	}
+
+	for i, td := range testData {
+		token := New(2).AddField("id", td.Value, common.DirectionAsc).Encode()
+
+		cursor, err := decodeCursor(token, common.CursorBasic, nil, nil)
+		if err != nil || cursor == nil {
+			t.Errorf("%v) Decode error: %v", i, err)
+			continue
+		}
+
+		v := cursor.Fields[0].Value
+		if tm, ok := v.(time.Time); ok && tm.Equal(td.Expected.(time.Time)) {
+			continue
+		}
+
+		if !reflect.DeepEqual(v, td.Expected) {
+			t.Errorf("%v) Not equal: %#v != %#v", i, v, td.Expected)
+		}
+	}
+
+	// cursor without value types
+	var f Field
+	if err := json.Unmarshal([]byte(`{"name":"id","value":5,"direction":"asc"}`), &f); err != nil || f.Value != float64(5) {
+		t.Errorf("Legacy field decode: %#v, %v", f, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"id","value":5,"type":"unknown","direction":"asc"}`), &f); err == nil {
+		t.Error("Unknown value type must fail")
+	}
+}
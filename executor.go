@@ -0,0 +1,177 @@
+package pagination
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+type (
+	// executor runs paginator queries over one base query
+	executor interface {
+		// find loads page rows to dst
+		find(dst interface{}, cursors ...*cursor.Cursor) error
+		// findPage loads rows in the cursor order skipping offset rows
+		findPage(dst interface{}, c *cursor.Cursor, offset int) error
+		// count returns number of rows matching conditions of all cursors, without limit
+		count(cursors ...*cursor.Cursor) (int64, error)
+		// exists checks rows after the cursor
+		exists(c *cursor.Cursor) (bool, error)
+		// countLimit returns number of rows, but not more than limit
+		countLimit(limit int64) (int64, error)
+		// estimate returns row estimate of the query planner
+		estimate() (int64, error)
+		// countQuery returns the query for Counter
+		countQuery() CountQuery
+		// context of the queries
+		context() context.Context
+	}
+
+	gormExecutor struct {
+		db *gorm.DB
+		tx *gorm.DB
+	}
+)
+
+// joinRelations joins belongs-to and has-one relations of nested cursor fields (Author__name) missing in tx.
+// Only relations of the model are joined, deeper nested fields must be joined by the caller
+func (e *gormExecutor) joinRelations(model interface{}, cursors ...*cursor.Cursor) {
+	if e.tx.Statement.Model != nil {
+		model = e.tx.Statement.Model
+	}
+
+	if model == nil {
+		return
+	}
+
+	var s *schema.Schema
+
+	for _, c := range cursors {
+		if c == nil {
+			continue
+		}
+
+		for _, f := range c.Fields {
+			name, column, nested := strings.Cut(strings.Trim(f.Name, `"`), "__")
+			if !nested || strings.Contains(column, "__") || e.joined(name) {
+				continue
+			}
+
+			if s == nil {
+				var err error
+				if s, err = cursor.Schema(model, e.tx.NamingStrategy); err != nil {
+					return
+				}
+			}
+
+			if rel, ok := s.Relationships.Relations[name]; ok && (rel.Type == schema.BelongsTo || rel.Type == schema.HasOne) {
+				tx := e.tx.Session(&gorm.Session{})
+				if tx.Statement.Model == nil {
+					// relation join needs schema of the model: db.Table("materials") or db.Where(...) has no model
+					tx = tx.Model(model)
+				}
+
+				e.tx = tx.Joins(name)
+			}
+		}
+	}
+}
+
+// joined reports whether tx joins the relation: Joins("Author") or raw join with its alias (LEFT JOIN users "Author" ON ...)
+func (e *gormExecutor) joined(name string) bool {
+	alias := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+
+	for _, j := range e.tx.Statement.Joins {
+		if j.Name == name || (strings.ContainsAny(j.Name, " \t\n") && alias.MatchString(j.Name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *gormExecutor) context() context.Context {
+	return e.db.Statement.Context
+}
+
+func (e *gormExecutor) table() *gorm.DB {
+	return e.db.Table("(?) as t", e.tx.Session(&gorm.Session{}))
+}
+
+func (e *gormExecutor) find(dst interface{}, cursors ...*cursor.Cursor) error {
+	q := e.table()
+	for _, c := range cursors {
+		q = q.Scopes(c.Scope())
+	}
+
+	// limit is taken from the first cursor as in cursor.SQLBuilder.Select, scopes of other cursors set their own
+	if len(cursors) > 0 && cursors[0].Limit > 0 {
+		q = q.Limit(cursors[0].Limit)
+	}
+
+	for k := range e.tx.Statement.Preloads {
+		q = q.Preload(k)
+	}
+
+	return q.Find(dst).Error
+}
+
+func (e *gormExecutor) findPage(dst interface{}, c *cursor.Cursor, offset int) error {
+	q := e.table().Scopes(c.Scope()).Offset(offset)
+
+	for k := range e.tx.Statement.Preloads {
+		q = q.Preload(k)
+	}
+
+	return q.Find(dst).Error
+}
+
+func (e *gormExecutor) count(cursors ...*cursor.Cursor) (count int64, err error) {
+	q := e.tx.Session(&gorm.Session{})
+	if len(cursors) > 0 {
+		q = e.table()
+		for _, c := range cursors {
+			q = q.Scopes(c.Scope())
+		}
+
+		q = q.Limit(-1)
+	}
+
+	err = e.db.Table("(?) as t", q).Select("count(1)").Limit(1).Count(&count).Error
+
+	return count, err
+}
+
+func (e *gormExecutor) exists(c *cursor.Cursor) (bool, error) {
+	var count int64
+
+	err := e.db.Table("(?) as t", e.table().Scopes(c.Scope())).Select("count(1)").Limit(1).Count(&count).Error
+
+	return count > 0, err
+}
+
+func (e *gormExecutor) countLimit(limit int64) (count int64, err error) {
+	err = e.db.Table("(?) as t", e.tx.Session(&gorm.Session{}).Limit(int(limit))).Select("count(1)").Limit(1).Count(&count).Error
+
+	return count, err
+}
+
+func (e *gormExecutor) estimate() (int64, error) {
+	var plan []byte
+
+	err := e.db.Raw("EXPLAIN (FORMAT JSON) SELECT * FROM (?) AS t", e.tx.Session(&gorm.Session{})).Row().Scan(&plan)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseExplain(plan)
+}
+
+func (e *gormExecutor) countQuery() CountQuery {
+	return CountQuery{Tx: e.tx.Session(&gorm.Session{})}
+}
@@ -0,0 +1,138 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+// Links are URLs of the pages around the current one, empty if there is no such page.
+// It is JSON:API links object and RFC 8288 Link header (see Header)
+type Links struct {
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// RequestURL returns absolute URL of the request, X-Forwarded-Proto header is used for scheme behind proxy
+func RequestURL(r *http.Request) *url.URL {
+	u := *r.URL
+
+	u.Scheme = "http"
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		u.Scheme = proto
+	}
+
+	u.Host = r.Host
+
+	return &u
+}
+
+// Links returns links of the pages after Find. Cursor params of u (cursor, after, before and sorting) are
+// replaced by the page cursor, other params are kept. Param names are taken from the request source.
+// In common.ModeOffset the links set the page param
+func (p *Paginator) Links(u *url.URL) Links {
+	if p.options.Mode == common.ModeOffset {
+		return p.pageLinks(u)
+	}
+
+	var links Links
+
+	if first := p.firstCursor(); first != nil {
+		links.First = p.link(u, first.Encode())
+		links.Last = p.link(u, p.lastCursor().Encode())
+	}
+
+	if p.PageInfo != nil {
+		if p.PageInfo.HasPrev {
+			links.Prev = p.link(u, p.PageInfo.Prev)
+		}
+
+		if p.PageInfo.HasNext {
+			links.Next = p.link(u, p.PageInfo.Next)
+		}
+	}
+
+	return links
+}
+
+// Header returns value of Link header: <url>; rel="next", ...
+func (l Links) Header() string {
+	var parts []string
+
+	for _, link := range []struct{ rel, url string }{{"first", l.First}, {"prev", l.Prev}, {"next", l.Next}, {"last", l.Last}} {
+		if link.url != "" {
+			parts = append(parts, "<"+link.url+`>; rel="`+link.rel+`"`)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// SetHeader sets Link header if there are links
+func (l Links) SetHeader(h http.Header) {
+	if value := l.Header(); value != "" {
+		h.Set("Link", value)
+	}
+}
+
+// firstCursor returns cursor of the first page in the request sort order: the cursor without values
+func (p *Paginator) firstCursor() *cursor.Cursor {
+	first := p.Cursor()
+	if first == nil {
+		return nil
+	}
+
+	first.Backward = false
+	for i := range first.Fields {
+		first.Fields[i].Value = nil
+		first.Fields[i].Null = false
+	}
+
+	return first
+}
+
+// lastCursor returns cursor of the last page: backward cursor without values
+func (p *Paginator) lastCursor() *cursor.Cursor {
+	return p.firstCursor().SetBackward()
+}
+
+func (p *Paginator) link(u *url.URL, token string) string {
+	params := p.queryParams()
+
+	link := *u
+	query := link.Query()
+
+	for _, name := range []string{params.After, params.Before, params.Sorting} {
+		query.Del(name)
+	}
+
+	query.Set(params.Cursor, token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// queryParams returns names of the request query params
+func (p *Paginator) queryParams() QueryParams {
+	switch s := p.options.Source.(type) {
+	case *funcSource:
+		return s.params
+	case *ginSource:
+		return s.params
+	}
+
+	if p.options.Source == nil && p.options.CustomRequest != nil {
+		return p.options.CustomRequest.Params.withDefaults()
+	}
+
+	return DefaultQueryParams
+}
@@ -0,0 +1,114 @@
+package pagination
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+// offsetQuery makes the decoder query of common.ModeOffset: sorting and per_page (or limit) without cursors.
+// Page number is 1 if the source has no page param
+func offsetQuery(source RequestSource, q cursor.Query) (cursor.Query, int, error) {
+	q.Cursor, q.After, q.Before = "", "", ""
+
+	ps, ok := source.(PageSource)
+	if !ok {
+		return q, 1, nil
+	}
+
+	if perPage := ps.PerPage(); perPage != "" {
+		q.Limit = perPage
+	}
+
+	page := 1
+
+	if s := ps.Page(); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return q, 0, common.ErrInvalidPage
+		}
+
+		page = n
+	}
+
+	return q, page, nil
+}
+
+// findOffset loads the page by number. One extra row is fetched to check the next page
+func (p *Paginator) findOffset(ctx context.Context, e executor, dst interface{}) error {
+	limit := p.cursor.Limit
+
+	c := p.Cursor()
+	if limit > 0 {
+		c.Limit++
+	}
+
+	if err := e.findPage(dst, c, (p.page-1)*limit); err != nil {
+		return contextErr(ctx, err)
+	}
+
+	more := limit > 0 && common.TrimSlice(dst, limit)
+
+	total, capped, countErr := p.total(e)
+	if countErr != nil && (ctx.Err() != nil || !p.options.IgnoreCountErrors) {
+		return contextErr(ctx, countErr)
+	}
+
+	p.PageInfo = &PageInfo{
+		HasNext:   more,
+		HasPrev:   p.page > 1,
+		TotalRows: int(total),
+		Page:      p.page,
+
+		TotalRowsCapped: capped,
+		CountFailed:     countErr != nil,
+	}
+
+	if countErr == nil && !capped && total >= 0 && limit > 0 {
+		p.PageInfo.TotalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+
+	return nil
+}
+
+// pageLinks returns links of common.ModeOffset with page numbers
+func (p *Paginator) pageLinks(u *url.URL) Links {
+	links := Links{First: p.pageLink(u, 1)}
+
+	if p.PageInfo == nil {
+		return links
+	}
+
+	if p.PageInfo.HasPrev {
+		links.Prev = p.pageLink(u, p.PageInfo.Page-1)
+	}
+
+	if p.PageInfo.HasNext {
+		links.Next = p.pageLink(u, p.PageInfo.Page+1)
+	}
+
+	if p.PageInfo.TotalPages > 0 {
+		links.Last = p.pageLink(u, p.PageInfo.TotalPages)
+	}
+
+	return links
+}
+
+func (p *Paginator) pageLink(u *url.URL, page int) string {
+	params := p.queryParams()
+
+	link := *u
+	query := link.Query()
+
+	for _, name := range []string{params.Cursor, params.After, params.Before} {
+		query.Del(name)
+	}
+
+	query.Set(params.Page, strconv.Itoa(page))
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
@@ -1,11 +1,12 @@
 package pagination
 
 import (
-	"log"
+	"context"
 	"reflect"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 
 	"github.com/rosberry/go-pagination/common"
 	"github.com/rosberry/go-pagination/cursor"
@@ -18,61 +19,101 @@ type (
 
 		cursor           *cursor.Cursor
 		additionalCursor *cursor.Cursor
+		decoded          bool
+		page             int
 	}
 
 	Options struct {
+		// Source of request values, GinContext with CustomRequest is used if it is nil
+		Source        RequestSource
 		GinContext    *gin.Context
 		DefaultCursor *cursor.Cursor
 		Model         interface{}
 		Limit         uint
+		MaxLimit      uint
+		LimitPolicy   common.LimitPolicy
 		DB            *gorm.DB
 		CustomRequest *RequestOptions
+		CursorCodec   cursor.Codec
+		// Placeholder of args in FindSQL queries
+		Placeholder cursor.Placeholder
+		// Quoter of identifiers in FindSQL queries, cursor.DoubleQuote by default. It sets LIMIT and NULLS syntax of the dialect too
+		Quoter cursor.Quoter
+		// Lookahead fetches Limit+1 rows to check the page after the cursor instead of count queries
+		Lookahead bool
+		// ProbeOpposite checks the page before the cursor with a query in Lookahead mode,
+		// otherwise it exists if the cursor points to a row
+		ProbeOpposite bool
+		// CountStrategy of PageInfo.TotalRows, CountCap is max rows of common.CountCapped
+		CountStrategy common.CountStrategy
+		CountCap      uint
+		// Counter replaces CountStrategy
+		Counter Counter
+		// IgnoreCountErrors makes failed count queries non-fatal: Find sets PageInfo.CountFailed instead of error
+		IgnoreCountErrors bool
+		// Logger of the paginator, global logger (common.SetLogger) is used if it is nil
+		Logger common.Logger
+		// FromEnd takes the page from the end of the list, of the rows after the request cursor or of the range (Relay "last")
+		FromEnd bool
+		// FromStart takes the page from the start of the list or of the rows before the request cursor (Relay "first")
+		FromStart bool
+		// Mode of pagination, common.ModeOffset uses page and per_page params (see PageSource) instead of cursors
+		Mode common.Mode
+		// Sortable fields of sorting, they are read from `cursor:"name,sortable"` tags of Model if nil.
+		// Any field of Model is sortable if there are no such tags
+		Sortable []cursor.SortField
+		// SortFormat of sorting param: JSON array, compact (-created_at,name) or AIP-132 (created_at desc, name).
+		// It is detected by default
+		SortFormat common.SortFormat
 	}
 
-	RequestGetter  func(c *gin.Context) (query string)
+	RequestGetter func(c *gin.Context) (query string)
+	// RequestOptions customize getters of gin request source. Params are names of query params without getters and in links
 	RequestOptions struct {
 		Cursor  RequestGetter
 		After   RequestGetter
 		Before  RequestGetter
 		Sorting RequestGetter
+		Limit   RequestGetter
+		Page    RequestGetter
+		PerPage RequestGetter
+		Params  QueryParams
 	}
 
 	PageInfo struct {
-		Next           string `json:"next"`
-		Prev           string `json:"prev"`
-		HasNext        bool   `json:"hasNext"`
-		HasPrev        bool   `json:"hasPrev"`
-		TotalRows      int    `json:"totalRows"`
-		RangeTruncated bool   `json:"rangeTruncated"`
+		Next            string `json:"next"`
+		Prev            string `json:"prev"`
+		First           string `json:"first"`
+		Last            string `json:"last"`
+		HasNext         bool   `json:"hasNext"`
+		HasPrev         bool   `json:"hasPrev"`
+		TotalRows       int    `json:"totalRows"`
+		TotalRowsCapped bool   `json:"totalRowsCapped"`
+		RangeTruncated  bool   `json:"rangeTruncated"`
+		CountFailed     bool   `json:"countFailed"`
+		Page            int    `json:"page,omitempty"`
+		TotalPages      int    `json:"totalPages,omitempty"`
 	}
 )
 
+// New paginator. Without Options.DefaultCursor rows are sorted by the model primary key
 func New(o Options) (*Paginator, error) {
-	if o.DefaultCursor == nil {
-		o.DefaultCursor = &cursor.Cursor{
-			Fields: []cursor.Field{
-				{
-					Name:      "id",
-					Value:     nil,
-					Direction: common.DirectionAsc,
-				},
-			},
-			Limit: func() int {
-				if o.Limit != 0 {
-					return int(o.Limit)
-				}
-				return common.DefaultLimit
-			}(),
-		}
-	}
-
 	return (&Paginator{}).new(o)
 }
 
 func (p *Paginator) new(o Options) (*Paginator, error) {
 	p.options = o
 
-	err := p.decode(o.CustomRequest)
+	if o.Source == nil && o.GinContext == nil {
+		return nil, common.ErrEmptyGinContextInPaginator
+	}
+
+	// without Model the request is decoded by the first Find, after SetModel or by the type of Query
+	if o.Model == nil {
+		return p, nil
+	}
+
+	err := p.decode()
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +129,30 @@ func (p *Paginator) SetModel(model interface{}) {
 	p.options.Model = model
 }
 
+// Cursor returns copy of the request cursor, it is nil before the request is decoded (see Options.Model)
+func (p *Paginator) Cursor() *cursor.Cursor {
+	if p.cursor == nil {
+		return nil
+	}
+
+	c := *p.cursor
+	c.Fields = append([]cursor.Field(nil), p.cursor.Fields...)
+
+	return &c
+}
+
+// Find finds page of tx rows to dst, queries use context of tx (tx.WithContext)
 func (p *Paginator) Find(tx *gorm.DB, dst interface{}) error {
+	ctx := context.Background()
+	if tx != nil && tx.Statement != nil && tx.Statement.Context != nil {
+		ctx = tx.Statement.Context
+	}
+
+	return p.FindContext(ctx, tx, dst)
+}
+
+// FindContext is Find with context of all paginator queries. It returns ctx.Err() if the context is done
+func (p *Paginator) FindContext(ctx context.Context, tx *gorm.DB, dst interface{}) error {
 	if p.options.Model == nil {
 		return common.ErrEmptyModelInPaginator
 	}
@@ -97,6 +161,30 @@ func (p *Paginator) Find(tx *gorm.DB, dst interface{}) error {
 		return common.ErrEmptyDBInPaginator
 	}
 
+	return p.find(ctx, &gormExecutor{db: p.options.DB.WithContext(ctx), tx: tx.WithContext(ctx)}, dst)
+}
+
+// Cursors returns encoded cursors of dst (slice or pointer to slice found by Find) elements in order, without queries.
+// Cursor of an element continues the list after it, as PageInfo.Next of the last element
+func (p *Paginator) Cursors(dst interface{}) ([]string, error) {
+	if p.cursor == nil {
+		return nil, common.ErrInvalidCursor
+	}
+
+	object := reflect.Indirect(reflect.ValueOf(dst))
+	if object.Kind() != reflect.Slice {
+		return nil, common.ErrInvalidFindDestinationNotSlice
+	}
+
+	cursors := make([]string, object.Len())
+	for i := range cursors {
+		cursors[i] = p.cursor.ToCursor(object.Index(i).Interface()).Encode()
+	}
+
+	return cursors, nil
+}
+
+func (p *Paginator) find(ctx context.Context, e executor, dst interface{}) error {
 	// check what dst is pointer to slice
 	if reflect.ValueOf(dst).Kind() != reflect.Ptr {
 		return common.ErrInvalidFindDestinationNotPointer
@@ -106,28 +194,61 @@ func (p *Paginator) Find(tx *gorm.DB, dst interface{}) error {
 		return common.ErrInvalidFindDestinationNotSlice
 	}
 
+	if !p.decoded {
+		if err := p.decode(); err != nil {
+			return err
+		}
+	}
+
 	// execute query
 	if p.cursor == nil {
 		return common.ErrInvalidCursor
 	}
-	// -------
-	var totalRowInPage int64
 
-	q := p.options.DB
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if g, ok := e.(*gormExecutor); ok {
+		g.joinRelations(p.options.Model, p.cursor, p.additionalCursor)
+	}
 
-	q = q.Table("(?) as t", tx.Session(&gorm.Session{})).Scopes(p.cursor.Scope())
-	if p.additionalCursor != nil {
-		q = q.Scopes(p.additionalCursor.Scope())
-		totalRowInPage = p.count(q.Session(&gorm.Session{}).Limit(-1))
+	if p.options.Mode == common.ModeOffset {
+		return p.findOffset(ctx, e, dst)
+	}
+	// -------
+	var (
+		totalRowInPage int64
+		rangeErr       error
+	)
+
+	cursors := []*cursor.Cursor{p.cursor}
+	if p.options.Lookahead && p.cursor.Limit > 0 {
+		lookahead := *p.cursor
+		lookahead.Limit++
+		cursors[0] = &lookahead
 	}
 
-	for k := range tx.Statement.Preloads {
-		q = q.Preload(k)
+	if p.additionalCursor != nil {
+		cursors = append(cursors, p.additionalCursor)
+		if !p.options.Lookahead {
+			totalRowInPage, rangeErr = p.count(e, common.QueryRangeCount, cursors...)
+			if rangeErr != nil && (ctx.Err() != nil || !p.options.IgnoreCountErrors) {
+				return contextErr(ctx, rangeErr)
+			}
+		}
 	}
-	err := q.Find(dst).Error
+
+	err := e.find(dst, cursors...)
 	// -------
 	if err != nil {
-		return err
+		return contextErr(ctx, err)
+	}
+
+	// extra row of lookahead is the first row of the next page
+	var more bool
+	if p.options.Lookahead && p.cursor.Limit > 0 {
+		more = common.TrimSlice(dst, p.cursor.Limit)
 	}
 
 	if p.cursor.Backward {
@@ -135,10 +256,18 @@ func (p *Paginator) Find(tx *gorm.DB, dst interface{}) error {
 	}
 
 	// calc paginationinfo
-	p.PageInfo = p.calcPageInfo(tx, dst)
+	p.PageInfo, err = p.calcPageInfo(e, dst, more)
+	if err != nil {
+		return contextErr(ctx, err)
+	}
 
-	if p.additionalCursor != nil {
-		if int64(reflect.Indirect(reflect.ValueOf(dst)).Len()) != totalRowInPage {
+	if p.additionalCursor != nil && p.PageInfo != nil {
+		switch {
+		case p.options.Lookahead:
+			p.PageInfo.RangeTruncated = more
+		case rangeErr != nil:
+			p.PageInfo.CountFailed = true
+		case int64(reflect.Indirect(reflect.ValueOf(dst)).Len()) != totalRowInPage:
 			p.PageInfo.RangeTruncated = true
 		}
 	}
@@ -146,96 +275,241 @@ func (p *Paginator) Find(tx *gorm.DB, dst interface{}) error {
 	return nil
 }
 
-func (p *Paginator) calcPageInfo(tx *gorm.DB, dst interface{}) *PageInfo {
+func (p *Paginator) calcPageInfo(e executor, dst interface{}, more bool) (*PageInfo, error) {
 	object := reflect.Indirect(reflect.ValueOf(dst))
 	if object.IsNil() || object.Len() == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// query for totalRow
-	totalRows := p.count(tx.Session(&gorm.Session{}))
+	totalRows, capped, countErr := p.total(e)
+	if countErr != nil && (e.context().Err() != nil || !p.options.IgnoreCountErrors) {
+		return nil, countErr
+	}
 
 	// last elem to nextCursor
 	nextCursor := p.cursor.ToCursor(object.Index(object.Len() - 1).Interface())
 
 	// first elem to prevCursor
-	prevCursor := p.cursor.ToCursor(object.Index(0).Interface())
-	// prevCursor.Backward = true
+	prevCursor := p.cursor.ToCursor(object.Index(0).Interface()).SetBackward()
+
+	hasNext, hasPrev, err := p.hasPages(e, nextCursor, prevCursor, more)
+	if err != nil {
+		return nil, err
+	}
 
 	// save paginationInfo to p
 	pageInfo := &PageInfo{
 		Next:      nextCursor.Encode(),
-		Prev:      prevCursor.SetBackward().Encode(),
-		HasNext:   p.checkPage(tx.Session(&gorm.Session{}), nextCursor.Scope()),
-		HasPrev:   p.checkPage(tx.Session(&gorm.Session{}), prevCursor.Scope()),
+		Prev:      prevCursor.Encode(),
+		First:     p.firstCursor().Encode(),
+		Last:      p.lastCursor().Encode(),
+		HasNext:   hasNext,
+		HasPrev:   hasPrev,
 		TotalRows: int(totalRows),
+
+		TotalRowsCapped: capped,
+		CountFailed:     countErr != nil,
 	}
 
-	return pageInfo
+	return pageInfo, nil
 }
 
-func (p *Paginator) decode(customRequest *RequestOptions) error {
-	if p.options.GinContext == nil {
-		return common.ErrEmptyGinContextInPaginator
+// hasPages checks pages after nextCursor and before prevCursor.
+// In Lookahead mode the page in the cursor direction exists if the extra row was fetched (more)
+func (p *Paginator) hasPages(e executor, nextCursor, prevCursor *cursor.Cursor, more bool) (hasNext, hasPrev bool, err error) {
+	if !p.options.Lookahead {
+		if hasNext, err = p.checkPage(e, common.QueryNextPage, nextCursor); err != nil {
+			return false, false, err
+		}
+
+		hasPrev, err = p.checkPage(e, common.QueryPrevPage, prevCursor)
+
+		return hasNext, hasPrev, err
 	}
 
-	sortingQuery := p.options.GinContext.Query("sorting")
-	cursorQuery := p.options.GinContext.Query("cursor")
+	// rows after the range are not fetched, but the before cursor points to one of them
+	ahead := more || p.additionalCursor.Positioned()
 
-	afterQuery := p.options.GinContext.Query("after")
-	beforeQuery := p.options.GinContext.Query("before")
+	opposite, query := prevCursor, common.QueryPrevPage
+	if p.cursor.Backward {
+		opposite, query = nextCursor, common.QueryNextPage
+	}
 
-	if customRequest != nil {
-		if customRequest.Sorting != nil {
-			sortingQuery = customRequest.Sorting(p.options.GinContext)
-		}
-		if customRequest.Cursor != nil {
-			cursorQuery = customRequest.Cursor(p.options.GinContext)
+	behind := p.cursor.Positioned()
+	if p.options.ProbeOpposite {
+		if behind, err = p.checkPage(e, query, opposite); err != nil {
+			return false, false, err
 		}
+	}
+
+	if p.cursor.Backward {
+		return behind, ahead, nil
+	}
+
+	return ahead, behind, nil
+}
+
+// contextErr returns ctx.Err() instead of query error if the context is done
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return err
+}
 
-		if customRequest.After != nil {
-			afterQuery = customRequest.After(p.options.GinContext)
+func (p *Paginator) decode() error {
+	source := p.options.Source
+	if source == nil {
+		if p.options.GinContext == nil {
+			return common.ErrEmptyGinContextInPaginator
 		}
-		if customRequest.Before != nil {
-			beforeQuery = customRequest.Before(p.options.GinContext)
+
+		source = FromGin(p.options.GinContext, p.options.CustomRequest)
+	}
+
+	decoder := &cursor.Decoder{
+		DefaultCursor: p.defaultCursor(),
+		Model:         p.options.Model,
+		Limit:         p.options.Limit,
+		MaxLimit:      p.options.MaxLimit,
+		LimitPolicy:   p.options.LimitPolicy,
+		Codec:         p.options.CursorCodec,
+		Logger:        p.options.Logger,
+		Sortable:      p.options.Sortable,
+		SortFormat:    p.options.SortFormat,
+		Namer:         p.namer(),
+	}
+
+	query := cursor.Query{
+		Sorting: source.Sorting(),
+		Cursor:  source.Cursor(),
+		After:   source.After(),
+		Before:  source.Before(),
+		Limit:   source.Limit(),
+	}
+
+	if p.options.Mode == common.ModeOffset {
+		var err error
+
+		query, p.page, err = offsetQuery(source, query)
+		if err != nil {
+			return err
 		}
 	}
 
-	cursor, additionalCursor, err := cursor.DecodeAction(sortingQuery, cursorQuery, afterQuery, beforeQuery, p.options.DefaultCursor, p.options.Model, p.options.Limit)
+	cursor, additionalCursor, err := decoder.Decode(query)
 	if err != nil {
 		return err
 	}
 
+	switch {
+	case p.options.FromEnd:
+		cursor, additionalCursor = fromEnd(cursor, additionalCursor)
+	case p.options.FromStart:
+		cursor, additionalCursor = fromStart(cursor, additionalCursor)
+	}
+
 	// cursor.DB = p.DB
 	p.cursor = cursor
 	p.additionalCursor = additionalCursor
+	p.decoded = true
 
 	return nil
 }
 
-func (p *Paginator) count(tx *gorm.DB) (count int64) {
-	if p.options.DB.Statement.Schema == nil {
-		log.Print("Schema is nil:")
+// fromEnd makes the request cursors take the page from the end (Relay "last"). Cursor without position goes backward
+// from the end of the list, after cursor becomes the bound of such cursor, range is read backward from its before cursor
+func fromEnd(c, bound *cursor.Cursor) (*cursor.Cursor, *cursor.Cursor) {
+	switch {
+	case bound != nil:
+		bound.Limit = c.Limit
+		return bound, c
+	case c.Backward:
+		return c, nil
+	case !c.Positioned():
+		return c.SetBackward(), nil
+	}
+
+	return withoutPosition(c).SetBackward(), c
+}
+
+// fromStart is fromEnd for the start of the list: before cursor becomes the bound of the cursor from the start
+func fromStart(c, bound *cursor.Cursor) (*cursor.Cursor, *cursor.Cursor) {
+	if bound != nil || !c.Backward {
+		return c, bound
+	}
+
+	start := withoutPosition(c)
+	start.Backward = false
+
+	if !c.Positioned() {
+		return start, nil
+	}
+
+	return start, c
+}
+
+// withoutPosition returns copy of the cursor without field values
+func withoutPosition(c *cursor.Cursor) *cursor.Cursor {
+	unpositioned := *c
+	unpositioned.Fields = make([]cursor.Field, len(c.Fields))
+
+	for i, f := range c.Fields {
+		f.Value, f.Null = nil, false
+		unpositioned.Fields[i] = f
 	}
-	if err := p.options.DB.Table("(?) as t", tx.Session(&gorm.Session{})).Select("count(1)").Limit(1).Count(&count).Error; err != nil {
-		log.Println(err)
-		return -1
+
+	return &unpositioned
+}
+
+// namer returns naming strategy of Options.DB, nil without DB
+func (p *Paginator) namer() schema.Namer {
+	if p.options.DB == nil || p.options.DB.Config == nil {
+		return nil
 	}
 
-	return
+	return p.options.DB.NamingStrategy
 }
 
-func (p *Paginator) checkPage(tx *gorm.DB, scope func(*gorm.DB) *gorm.DB) (isExist bool) {
-	var count int64
+// defaultCursor returns Options.DefaultCursor or cursor by the model primary key ("id" if there is no key)
+func (p *Paginator) defaultCursor() *cursor.Cursor {
+	if p.options.DefaultCursor != nil {
+		return p.options.DefaultCursor
+	}
+
+	c := cursor.New(common.DefaultLimit)
+	if p.options.Limit != 0 {
+		c.Limit = int(p.options.Limit)
+	}
+
+	names := cursor.PrimaryKey(p.options.Model, p.namer())
+	if len(names) == 0 {
+		names = []string{"id"}
+	}
+
+	for _, name := range names {
+		c.AddField(name, nil, common.DirectionAsc)
+	}
+
+	return c
+}
 
-	if err := p.options.DB.Table("(?) as t", tx.Session(&gorm.Session{}).Scopes(scope)).Select("count(1)").Limit(1).Count(&count).Error; err != nil {
-		log.Println(err)
-		return
+func (p *Paginator) count(e executor, query string, cursors ...*cursor.Cursor) (int64, error) {
+	count, err := e.count(cursors...)
+	if err != nil {
+		return -1, &common.QueryError{Query: query, Err: err}
 	}
 
-	if count > 0 {
-		return true
+	return count, nil
+}
+
+func (p *Paginator) checkPage(e executor, query string, c *cursor.Cursor) (bool, error) {
+	isExist, err := e.exists(c)
+	if err != nil {
+		return false, &common.QueryError{Query: query, Err: err}
 	}
 
-	return
+	return isExist, nil
 }
@@ -0,0 +1,110 @@
+package pagination
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+func TestDialectNullsPaging(t *testing.T) {
+	type Item struct {
+		ID       uint
+		PublicAt *int `json:"publicAt"`
+	}
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.AutoMigrate(&Item{}); err != nil {
+		t.Fatal(err)
+	}
+
+	value := func(v int) *int { return &v }
+	if err := db.Create([]Item{{1, value(2)}, {2, nil}, {3, value(1)}, {4, nil}, {5, value(3)}, {6, value(2)}, {7, nil}}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finders := map[string]func(p *Paginator, items *[]Item) error{
+		"gorm": func(p *Paginator, items *[]Item) error {
+			return p.Find(db.Model(&Item{}), items)
+		},
+		"double quote": func(p *Paginator, items *[]Item) error {
+			return p.FindSQL(sqlDB, "SELECT * FROM items", nil, items)
+		},
+		// CASE expression instead of NULLS FIRST/LAST as in MySQL and SQL Server
+		"backtick": func(p *Paginator, items *[]Item) error {
+			p.options.Quoter = cursor.Backtick
+			return p.FindSQL(sqlDB, "SELECT * FROM items", nil, items)
+		},
+	}
+
+	// empty nulls order is the same as in PostgreSQL: nulls are last in asc and first in desc
+	orders := map[string]string{
+		"publicAt":  "public_at asc NULLS LAST, id asc",
+		"-publicAt": "public_at desc NULLS FIRST, id asc",
+	}
+
+	for sorting, orderBy := range orders {
+		var expected []uint
+		if err := db.Raw("SELECT id FROM items ORDER BY " + orderBy).Scan(&expected).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		for name, find := range finders {
+			page := func(values url.Values) ([]uint, *PageInfo) {
+				paginator, err := New(Options{Source: FromValues(values), Model: &Item{}, DB: db, Limit: 2})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var items []Item
+				if err := find(paginator, &items); err != nil {
+					t.Fatal(err)
+				}
+
+				ids := make([]uint, 0, len(items))
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+
+				return ids, paginator.PageInfo
+			}
+
+			ids, info := page(url.Values{"sorting": {sorting}})
+			forward := ids
+
+			for info.HasNext {
+				ids, info = page(url.Values{"after": {info.Next}})
+				forward = append(forward, ids...)
+			}
+
+			if !reflect.DeepEqual(forward, expected) {
+				t.Errorf("%s %s) Wrong forward pages: %v, expected: %v", name, sorting, forward, expected)
+			}
+
+			backward := ids
+
+			for info.HasPrev {
+				ids, info = page(url.Values{"before": {info.Prev}})
+				backward = append(ids, backward...)
+			}
+
+			if !reflect.DeepEqual(backward, expected) {
+				t.Errorf("%s %s) Wrong backward pages: %v, expected: %v", name, sorting, backward, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package pagination
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+func TestRowValuesIndexScan(t *testing.T) {
+	tx := liveDB().Begin()
+	defer tx.Rollback()
+
+	for _, sql := range []string{
+		"CREATE INDEX materials_comment_id_idx ON materials (comment, id)",
+		"SET LOCAL enable_seqscan = off", // fixture tables are too small for the index otherwise
+	} {
+		if err := tx.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// row values are used for NOT NULL fields only
+	c := cursor.New(pageLimit,
+		cursor.Field{Name: "comment", Value: "C", Direction: common.DirectionAsc, NotNull: true},
+		cursor.Field{Name: "id", Value: 3, Direction: common.DirectionAsc, NotNull: true},
+	)
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Model(&Material{}).Scopes(c.Scope()).Find(&[]Material{}).Statement
+
+	var raw []byte
+	if err := tx.Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Row().Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	type node struct {
+		NodeType  string `json:"Node Type"`
+		IndexName string `json:"Index Name"`
+		IndexCond string `json:"Index Cond"`
+		Plans     []node `json:"Plans"`
+	}
+
+	var plan []struct{ Plan node }
+	if err := json.Unmarshal(raw, &plan); err != nil || len(plan) == 0 {
+		t.Fatalf("Wrong plan: %s %v", raw, err)
+	}
+
+	var find func(n node) *node
+	find = func(n node) *node {
+		if n.IndexName == "materials_comment_id_idx" {
+			return &n
+		}
+
+		for _, child := range n.Plans {
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+
+		return nil
+	}
+
+	if scan := find(plan[0].Plan); scan == nil || scan.IndexCond == "" {
+		t.Errorf("Keyset condition does not use index scan: %s\n%s", stmt.SQL.String(), raw)
+	}
+}
@@ -0,0 +1,110 @@
+package pagination
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+func TestJoinRelations(t *testing.T) {
+	type (
+		User struct {
+			ID   uint
+			Name string
+		}
+
+		Comment struct {
+			ID         uint
+			MaterialID uint
+		}
+
+		Material struct {
+			ID       uint
+			Title    string
+			UserID   uint
+			Author   User `gorm:"foreignKey:UserID"`
+			Comments []Comment
+		}
+	)
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := `SELECT * FROM (SELECT "materials"."id","materials"."title","materials"."user_id","Author"."id" AS "Author__id","Author"."name" AS "Author__name" ` +
+		`FROM "materials" LEFT JOIN "users" "Author" ON "materials"."user_id" = "Author"."id") as t ORDER BY "Author__name" desc NULLS FIRST,"id" asc LIMIT 3`
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "title", "user_id", "Author__id", "Author__name"}).
+			AddRow(1, "a", 2, 2, "Z").AddRow(3, "b", 1, 1, "A")
+	}
+
+	rawJoin := `LEFT JOIN "users" "Author" ON "materials"."user_id" = "Author"."id"`
+	rawSelect := `"materials".*, "Author"."name" AS "Author__name"`
+
+	testData := []struct {
+		Tx    *gorm.DB
+		Joins int
+		Page  string
+	}{
+		{db.Model(&Material{}), 0, page},
+		{db.Model(&Material{}).Joins("Author"), 1, page},
+		{db.Table("materials"), 0, page},
+		{db.Where("materials.title <> ?", ""), 0, strings.Replace(page, `"Author"."id")`, `"Author"."id" WHERE materials.title <> $1)`, 1)},
+		{db.Model(&Material{}).Select(rawSelect).Joins(rawJoin), 1, `SELECT * FROM (SELECT ` + rawSelect + ` FROM "materials" ` + rawJoin + `) as t ORDER BY "Author__name" desc NULLS FIRST,"id" asc LIMIT 3`},
+	}
+
+	for _, td := range testData {
+		tx := td.Tx
+
+		paginator, err := New(Options{
+			Source:        FromValues(url.Values{"sorting": {"-author.name"}}),
+			Model:         &Material{},
+			DB:            db,
+			Limit:         2,
+			Lookahead:     true,
+			CountStrategy: common.CountNone,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mock.ExpectQuery(td.Page).WillReturnRows(rows())
+
+		var materials []Material
+		if err := paginator.Find(tx, &materials); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(materials) != 2 || materials[0].Author.Name != "Z" || materials[1].Title != "b" {
+			t.Errorf("Wrong materials: %+v", materials)
+		}
+
+		next := cursor.New(2).AddField("Author__name", "A", common.DirectionDesc).AddField("id", uint(3), common.DirectionAsc).Encode()
+		if p := paginator.PageInfo; p == nil || p.Next != next {
+			t.Errorf("Wrong page info: %+v", p)
+		}
+
+		// the query of the caller is not changed
+		if len(tx.Statement.Joins) != td.Joins {
+			t.Errorf("Wrong joins of the query: %+v", tx.Statement.Joins)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,115 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+func TestLinks(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	params := QueryParams{Cursor: "page_token", Sorting: "sort"}
+	token := cursor.New(2).AddField("id", 2, common.DirectionDesc).Encode()
+
+	r := httptest.NewRequest("GET", "/items?status=new&page_token="+url.QueryEscape(token), nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+
+	paginator, err := New(Options{
+		Source:        params.FromRequest(r),
+		Model:         &Item{},
+		Lookahead:     true,
+		CountStrategy: common.CountNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(".*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var items []Item
+	if err := paginator.FindSQL(db, "SELECT * FROM items", nil, &items); err != nil {
+		t.Fatal(err)
+	}
+
+	links := paginator.Links(RequestURL(r))
+
+	link := func(token string) string {
+		return "https://example.com/items?" + url.Values{"status": {"new"}, "page_token": {token}}.Encode()
+	}
+
+	first := link(cursor.New(2, cursor.Field{Name: "id", Direction: common.DirectionDesc}).Encode())
+	if links.First != first || links.Prev != link(paginator.PageInfo.Prev) || links.Next != "" || links.Last != link(paginator.PageInfo.Last) {
+		t.Errorf("Wrong links: %+v", links)
+	}
+
+	if header := links.Header(); header != "<"+links.First+`>; rel="first", <`+links.Prev+`>; rel="prev", <`+links.Last+`>; rel="last"` {
+		t.Errorf("Wrong Link header: %s", header)
+	}
+
+	w := httptest.NewRecorder()
+	links.SetHeader(w.Header())
+
+	if w.Header().Get("Link") != links.Header() {
+		t.Errorf("Link header is not set: %v", w.Header())
+	}
+
+	if (Links{}).SetHeader(w.Header()); w.Header().Get("Link") != links.Header() {
+		t.Error("Empty links must not change Link header")
+	}
+}
+
+func TestFirstLastTokens(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	first := cursor.New(2, cursor.Field{Name: "id", Direction: common.DirectionAsc})
+	last := cursor.New(2, cursor.Field{Name: "id", Direction: common.DirectionAsc}).SetBackward()
+
+	paginator, err := New(Options{
+		Source:        FromValues(url.Values{"cursor": {last.Encode()}}),
+		Model:         &Item{},
+		Lookahead:     true,
+		CountStrategy: common.CountNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT * FROM (SELECT * FROM items) AS t ORDER BY "id" desc LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(4).AddRow(3))
+
+	var items []Item
+	if err := paginator.FindSQL(db, "SELECT * FROM items", nil, &items); err != nil {
+		t.Fatal(err)
+	}
+
+	p := paginator.PageInfo
+	if len(items) != 2 || items[0].ID != 4 || items[1].ID != 5 || p.HasNext || !p.HasPrev {
+		t.Errorf("Wrong last page: %+v %+v", items, p)
+	}
+
+	if p.First != first.Encode() || p.Last != last.Encode() {
+		t.Errorf("Wrong first/last tokens: %s %s", p.First, p.Last)
+	}
+}
@@ -201,8 +201,8 @@ func TestMainFlow(t *testing.T) {
 			Result: r{
 				IDs: []uint{2, 4},
 				PageInfo: &PageInfo{
-					Next:    cursor.New(pageLimit).AddField(`"Author__name"`, "A", common.DirectionAsc).AddField("id", 4, common.DirectionAsc).Encode(),
-					Prev:    cursor.New(pageLimit).AddField(`"Author__name"`, "A", common.DirectionAsc).AddField("id", 2, common.DirectionAsc).SetBackward().Encode(),
+					Next:    cursor.New(pageLimit).AddField("Author__name", "A", common.DirectionAsc).AddField("id", 4, common.DirectionAsc).Encode(),
+					Prev:    cursor.New(pageLimit).AddField("Author__name", "A", common.DirectionAsc).AddField("id", 2, common.DirectionAsc).SetBackward().Encode(),
 					HasNext: true, HasPrev: false, TotalRows: 7,
 				},
 			},
@@ -271,8 +271,8 @@ func TestMainFlow(t *testing.T) {
 			Result: r{
 				IDs: []uint{2, 4, 6, 3},
 				PageInfo: &PageInfo{
-					Next:    cursor.New(4).AddField(`"Author__id"`, 3, common.DirectionAsc).AddField(`claps`, 1, common.DirectionDesc).AddField("id", 3, common.DirectionDesc).Encode(),
-					Prev:    cursor.New(4).AddField(`"Author__id"`, 1, common.DirectionAsc).AddField(`claps`, 1, common.DirectionDesc).AddField("id", 2, common.DirectionDesc).SetBackward().Encode(),
+					Next:    cursor.New(4).AddField("Author__id", 3, common.DirectionAsc).AddField(`claps`, 1, common.DirectionDesc).AddField("id", 3, common.DirectionDesc).Encode(),
+					Prev:    cursor.New(4).AddField("Author__id", 1, common.DirectionAsc).AddField(`claps`, 1, common.DirectionDesc).AddField("id", 2, common.DirectionDesc).SetBackward().Encode(),
 					HasNext: true, HasPrev: false, TotalRows: 7,
 				},
 			},
@@ -643,16 +643,16 @@ func materialsToResultIDs(materials []Material) (IDs []uint) {
 	return IDs
 }
 
-func convertTime(t string) string {
+func convertTime(t string) time.Time {
 	//"2020-12-31T23:56:59Z"
 	format := "2006-01-02T15:04:05Z07:00"
 	tParsed, err := time.Parse(format, t)
 	if err != nil {
 		log.Print(err)
-		return t
+		return time.Time{}
 	}
 
-	return tParsed.Local().Format(format)
+	return tParsed.Local()
 }
 
 // ------------ Code example
@@ -0,0 +1,85 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+func TestOffsetMode(t *testing.T) {
+	type Item struct {
+		ID      uint
+		Comment string
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	values := url.Values{
+		"page":     {"2"},
+		"per_page": {"2"},
+		"sorting":  {`[{"field": "comment", "direction": "desc"}]`},
+		"after":    {"ignored"},
+	}
+
+	paginator, err := New(Options{
+		Source: FromValues(values),
+		Model:  &Item{},
+		Mode:   common.ModeOffset,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := "SELECT * FROM items"
+
+	mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t ORDER BY "comment" desc NULLS FIRST, "id" asc LIMIT 3 OFFSET 2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "comment"}).AddRow(3, "c").AddRow(4, "b").AddRow(5, "a"))
+	mock.ExpectQuery("SELECT count(1) FROM (SELECT * FROM (" + query + ") AS t) AS c").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	var items []Item
+	if err := paginator.FindSQL(db, query, nil, &items); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if len(items) != 2 || items[0].ID != 3 || items[1].ID != 4 {
+		t.Errorf("Wrong items: %+v", items)
+	}
+
+	if p := paginator.PageInfo; p == nil || p.Page != 2 || p.TotalPages != 3 || p.TotalRows != 5 || !p.HasNext || !p.HasPrev || p.Next != "" {
+		t.Errorf("Wrong page info: %+v", p)
+	}
+
+	u, _ := url.Parse("https://example.com/items?" + values.Encode())
+	link := func(page string) string {
+		v := url.Values{"page": {page}, "per_page": values["per_page"], "sorting": values["sorting"]}
+		return "https://example.com/items?" + v.Encode()
+	}
+
+	if links := paginator.Links(u); links != (Links{First: link("1"), Prev: link("1"), Next: link("3"), Last: link("3")}) {
+		t.Errorf("Wrong links: %+v", links)
+	}
+
+	for _, page := range []string{"0", "-1", "a"} {
+		paginator, err := New(Options{
+			Source: FromValues(url.Values{"page": {page}}),
+			Model:  &Item{},
+			Mode:   common.ModeOffset,
+		})
+		if !errors.Is(err, common.ErrInvalidPage) {
+			t.Errorf("Expected ErrInvalidPage for page %q, got: %v %v", page, paginator, err)
+		}
+	}
+}
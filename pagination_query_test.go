@@ -0,0 +1,79 @@
+package pagination
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
+)
+
+func TestQuery(t *testing.T) {
+	type Item struct {
+		ID      uint
+		Comment string
+	}
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := func() Options {
+		return Options{
+			Source:        FromValues(url.Values{"sorting": {`[{"field": "comment", "direction": "desc"}]`}, "limit": {"2"}}),
+			DB:            db,
+			Lookahead:     true,
+			CountStrategy: common.CountNone,
+		}
+	}
+
+	p, err := New(options())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM (SELECT * FROM "items") as t ORDER BY "comment" desc NULLS FIRST,"id" asc LIMIT 3`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "comment"}).AddRow(3, "c").AddRow(1, "b").AddRow(2, "a"))
+
+	page, err := Query[Item](context.Background(), p, db.Table("items"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Items) != 2 || page.Items[0].Comment != "c" || page.PageInfo == nil || !page.PageInfo.HasNext || page.PageInfo.HasPrev {
+		t.Errorf("Wrong page: %+v %+v", page.Items, page.PageInfo)
+	}
+
+	p, err = New(options())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM (SELECT * FROM items WHERE id > ?) AS t ORDER BY "comment" desc NULLS FIRST, "id" asc LIMIT 3`)).
+		WithArgs(0).WillReturnRows(sqlmock.NewRows([]string{"id", "comment"}).AddRow(3, "c"))
+
+	ptrPage, err := QuerySQL[*Item](context.Background(), p, sqlDB, "SELECT * FROM items WHERE id > ?", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ptrPage.Items) != 1 || ptrPage.Items[0].ID != 3 || ptrPage.PageInfo.HasNext {
+		t.Errorf("Wrong page: %+v %+v", ptrPage.Items, ptrPage.PageInfo)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+type echoContext url.Values
+
+func (c echoContext) QueryParam(name string) string {
+	return url.Values(c).Get(name)
+}
+
+func TestRequestSource(t *testing.T) {
+	next := cursor.New(pageLimit).AddField("id", 6, common.DirectionDesc).Encode()
+	values := url.Values{"cursor": {next}, "limit": {"3"}}
+	custom := url.Values{"page_token": {next}, "page_size": {"3"}}
+
+	req := httptest.NewRequest("GET", "/list?"+values.Encode(), nil)
+
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("GET", "/list?"+custom.Encode(), nil)
+
+	sources := []RequestSource{
+		FromRequest(req),
+		FromValues(values),
+		FromEcho(echoContext(values)),
+		FromFunc(values.Get),
+		QueryParams{Cursor: "page_token", Limit: "page_size"}.FromValues(custom),
+		FromGin(ginCtx, &RequestOptions{
+			Cursor: func(c *gin.Context) string { return c.Query("page_token") },
+			Limit:  func(c *gin.Context) string { return c.Query("page_size") },
+		}),
+	}
+
+	for i, source := range sources {
+		paginator, err := New(Options{
+			Source: source,
+			Model:  &Material{},
+		})
+		if err != nil {
+			t.Errorf("%v) Unexpected error: %v", i, err)
+			continue
+		}
+
+		if paginator.cursor.Limit != 3 || len(paginator.cursor.Fields) != 1 || paginator.cursor.Fields[0].Direction != common.DirectionDesc {
+			t.Errorf("%v) Wrong cursor: %#v", i, paginator.cursor)
+		}
+	}
+
+	if _, err := New(Options{Model: &Material{}}); err != common.ErrEmptyGinContextInPaginator {
+		t.Errorf("Expected ErrEmptyGinContextInPaginator, got: %v", err)
+	}
+}
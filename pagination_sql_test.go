@@ -0,0 +1,526 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+func TestFindSQL(t *testing.T) {
+	type Item struct {
+		ID      uint
+		Comment string `db:"comment"`
+		UserID  uint
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	after := cursor.New(2).AddField("id", 3, common.DirectionAsc).Encode()
+
+	paginator, err := New(Options{
+		Source:      FromValues(url.Values{"after": {after}}),
+		Model:       &Item{},
+		Placeholder: cursor.Dollar,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := "SELECT * FROM items WHERE user_id = $1"
+	page := "SELECT * FROM (" + query + `) AS t WHERE (("id" > $2)) ORDER BY "id" asc LIMIT 2`
+
+	mock.ExpectQuery(page).WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "comment", "user_id"}).AddRow(4, "d", 1).AddRow(5, "e", 1))
+	mock.ExpectQuery("SELECT count(1) FROM (SELECT * FROM (" + query + ") AS t) AS c").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	mock.ExpectQuery("SELECT count(1) FROM ("+page+") AS c").WithArgs(1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT count(1) FROM (SELECT * FROM ("+query+`) AS t WHERE (("id" < $2)) ORDER BY "id" desc LIMIT 2) AS c`).WithArgs(1, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	var items []Item
+	if err := paginator.FindSQL(db, query, []interface{}{1}, &items); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || items[0].ID != 4 || items[1].Comment != "e" || items[1].UserID != 1 {
+		t.Errorf("Wrong items: %+v", items)
+	}
+
+	if p := paginator.PageInfo; p == nil || p.TotalRows != 7 || !p.HasNext || !p.HasPrev {
+		t.Errorf("Wrong page info: %+v", p)
+	}
+
+	cursors, err := paginator.Cursors(&items)
+	if err != nil || len(cursors) != 2 || cursors[1] != paginator.PageInfo.Next {
+		t.Fatalf("Wrong cursors: %v %v", cursors, err)
+	}
+
+	if c := cursor.New(2).AddField("id", 4, common.DirectionAsc).Encode(); cursors[0] != c {
+		t.Errorf("Wrong cursor of the first item: %s != %s", cursors[0], c)
+	}
+
+	if _, err := paginator.Cursors(items[0]); !errors.Is(err, common.ErrInvalidFindDestinationNotSlice) {
+		t.Errorf("Expected ErrInvalidFindDestinationNotSlice, got: %v", err)
+	}
+}
+
+func TestFindSQLLookahead(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	query := "SELECT * FROM items"
+	total := "SELECT count(1) FROM (SELECT * FROM (" + query + ") AS t) AS c"
+	ids := func(values ...int) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, v := range values {
+			rows.AddRow(v)
+		}
+
+		return rows
+	}
+
+	type TestDataStruct struct {
+		Values        url.Values
+		ProbeOpposite bool
+		Expect        func(mock sqlmock.Sqlmock)
+		IDs           []uint
+		PageInfo      PageInfo
+	}
+
+	testData := []TestDataStruct{
+		{
+			Values: url.Values{},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t ORDER BY "id" asc LIMIT 3`).WillReturnRows(ids(1, 2, 3))
+			},
+			IDs:      []uint{1, 2},
+			PageInfo: PageInfo{HasNext: true, HasPrev: false},
+		},
+		{
+			Values: url.Values{"after": {cursor.New(2).AddField("id", 2, common.DirectionAsc).Encode()}},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t WHERE (("id" > $1)) ORDER BY "id" asc LIMIT 3`).WithArgs(2).WillReturnRows(ids(3, 4))
+			},
+			IDs:      []uint{3, 4},
+			PageInfo: PageInfo{HasNext: false, HasPrev: true},
+		},
+		{
+			Values:        url.Values{"before": {cursor.New(2).AddField("id", 3, common.DirectionAsc).Encode()}},
+			ProbeOpposite: true,
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t WHERE (("id" < $1)) ORDER BY "id" desc LIMIT 3`).WithArgs(3).WillReturnRows(ids(2, 1))
+				mock.ExpectQuery(`SELECT count(1) FROM (SELECT * FROM (` + query + `) AS t WHERE (("id" > $1)) ORDER BY "id" asc LIMIT 2) AS c`).WithArgs(2).WillReturnRows(ids(0))
+			},
+			IDs:      []uint{1, 2},
+			PageInfo: PageInfo{HasNext: false, HasPrev: false},
+		},
+		{
+			Values: url.Values{
+				"after":  {cursor.New(2).AddField("id", 1, common.DirectionAsc).Encode()},
+				"before": {cursor.New(2).AddField("id", 5, common.DirectionAsc).Encode()},
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM (`+query+`) AS t WHERE (("id" > $1)) AND (("id" < $2)) ORDER BY "id" asc LIMIT 3`).WithArgs(1, 5).WillReturnRows(ids(2, 3, 4))
+			},
+			IDs:      []uint{2, 3},
+			PageInfo: PageInfo{HasNext: true, HasPrev: true, RangeTruncated: true},
+		},
+	}
+
+	for i, td := range testData {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paginator, err := New(Options{
+			Source:        FromValues(td.Values),
+			Model:         &Item{},
+			Limit:         2,
+			Placeholder:   cursor.Dollar,
+			Lookahead:     true,
+			ProbeOpposite: td.ProbeOpposite,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mock.MatchExpectationsInOrder(false)
+		td.Expect(mock)
+		mock.ExpectQuery(total).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+		var items []Item
+		if err := paginator.FindSQL(db, query, nil, &items); err != nil {
+			t.Errorf("%v) Unexpected error: %v", i, err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%v) %v", i, err)
+		}
+
+		var got []uint
+		for _, item := range items {
+			got = append(got, item.ID)
+		}
+
+		p := paginator.PageInfo
+		if !reflect.DeepEqual(got, td.IDs) || p == nil || p.HasNext != td.PageInfo.HasNext ||
+			p.HasPrev != td.PageInfo.HasPrev || p.RangeTruncated != td.PageInfo.RangeTruncated || p.TotalRows != 4 {
+			t.Errorf("%v) Wrong page: %v %+v", i, got, p)
+		}
+
+		db.Close()
+	}
+}
+
+func TestCountStrategy(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	query := "SELECT * FROM items"
+	count := func(n int) *sqlmock.Rows { return sqlmock.NewRows([]string{"count"}).AddRow(n) }
+
+	type TestDataStruct struct {
+		Options   Options
+		Expect    func(mock sqlmock.Sqlmock)
+		TotalRows int
+		Capped    bool
+	}
+
+	testData := []TestDataStruct{
+		{
+			Options:   Options{CountStrategy: common.CountNone},
+			Expect:    func(mock sqlmock.Sqlmock) {},
+			TotalRows: -1,
+		},
+		{
+			Options: Options{CountStrategy: common.CountCapped, CountCap: 10},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count(1) FROM (SELECT * FROM (" + query + ") AS t LIMIT 11) AS c").WillReturnRows(count(11))
+			},
+			TotalRows: 10,
+			Capped:    true,
+		},
+		{
+			Options: Options{CountStrategy: common.CountCapped, CountCap: 10},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count(1) FROM (SELECT * FROM (" + query + ") AS t LIMIT 11) AS c").WillReturnRows(count(7))
+			},
+			TotalRows: 7,
+		},
+		{
+			Options: Options{CountStrategy: common.CountEstimated},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("EXPLAIN (FORMAT JSON) SELECT * FROM (" + query + ") AS t").
+					WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 12345}}]`))
+			},
+			TotalRows: 12345,
+		},
+		{
+			Options: Options{Counter: func(q CountQuery) (int64, error) {
+				if q.SQL != query {
+					t.Errorf("Wrong count query: %s", q.SQL)
+				}
+
+				return 42, nil
+			}},
+			Expect:    func(mock sqlmock.Sqlmock) {},
+			TotalRows: 42,
+		},
+	}
+
+	for i, td := range testData {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		td.Options.Source = FromValues(url.Values{})
+		td.Options.Model = &Item{}
+		td.Options.Limit = 2
+		td.Options.Lookahead = true
+
+		paginator, err := New(td.Options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t ORDER BY "id" asc LIMIT 3`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		td.Expect(mock)
+
+		var items []Item
+		if err := paginator.FindSQL(db, query, nil, &items); err != nil {
+			t.Errorf("%v) Unexpected error: %v", i, err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%v) %v", i, err)
+		}
+
+		if p := paginator.PageInfo; p == nil || p.TotalRows != td.TotalRows || p.TotalRowsCapped != td.Capped {
+			t.Errorf("%v) Wrong page info: %+v", i, p)
+		}
+
+		db.Close()
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	query := "SELECT * FROM items"
+	page := `SELECT * FROM (` + query + `) AS t ORDER BY "id" asc LIMIT 2`
+	total := "SELECT count(1) FROM (SELECT * FROM (" + query + ") AS t) AS c"
+	errDB := errors.New("connection lost")
+
+	type TestDataStruct struct {
+		IgnoreCountErrors bool
+		Expect            func(mock sqlmock.Sqlmock)
+		Query             string
+	}
+
+	testData := []TestDataStruct{
+		{
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(total).WillReturnError(errDB)
+			},
+			Query: common.QueryTotalCount,
+		},
+		{
+			IgnoreCountErrors: true,
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(total).WillReturnError(errDB)
+				mock.ExpectQuery(`SELECT count(1) FROM (SELECT * FROM (` + query + `) AS t WHERE (("id" > $1)) ORDER BY "id" asc LIMIT 2) AS c`).
+					WithArgs(2).WillReturnError(errDB)
+			},
+			Query: common.QueryNextPage,
+		},
+		{
+			IgnoreCountErrors: true,
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(total).WillReturnError(errDB)
+				mock.ExpectQuery(`SELECT count(1) FROM (SELECT * FROM (` + query + `) AS t WHERE (("id" > $1)) ORDER BY "id" asc LIMIT 2) AS c`).
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(`SELECT count(1) FROM (SELECT * FROM (` + query + `) AS t WHERE (("id" < $1)) ORDER BY "id" desc LIMIT 2) AS c`).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+		},
+	}
+
+	for i, td := range testData {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paginator, err := New(Options{
+			Source:            FromValues(url.Values{}),
+			Model:             &Item{},
+			Limit:             2,
+			Placeholder:       cursor.Dollar,
+			IgnoreCountErrors: td.IgnoreCountErrors,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mock.ExpectQuery(page).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		td.Expect(mock)
+
+		var items []Item
+		err = paginator.FindSQL(db, query, nil, &items)
+
+		var queryErr *common.QueryError
+		switch {
+		case td.Query == "" && err != nil:
+			t.Errorf("%v) Unexpected error: %v", i, err)
+		case td.Query == "" && (paginator.PageInfo == nil || !paginator.PageInfo.CountFailed || paginator.PageInfo.TotalRows != -1):
+			t.Errorf("%v) Wrong page info: %+v", i, paginator.PageInfo)
+		case td.Query != "" && (!errors.As(err, &queryErr) || queryErr.Query != td.Query || !errors.Is(err, errDB)):
+			t.Errorf("%v) Expected %s QueryError, got: %v", i, td.Query, err)
+		}
+
+		db.Close()
+	}
+}
+
+func TestFindSQLWithoutModel(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	paginator, err := New(Options{Source: FromValues(url.Values{"sorting": {"-name"}})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var items []Item
+	if err := paginator.FindSQL(db, "SELECT * FROM items", nil, &items); !errors.Is(err, common.ErrEmptyModelInPaginator) {
+		t.Errorf("Expected ErrEmptyModelInPaginator, got: %v", err)
+	}
+}
+
+func TestFindContext(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paginator, err := New(Options{Source: FromValues(url.Values{}), Model: &Item{}, DB: db, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var items []Item
+	if err := paginator.FindContext(canceled, db.Table("items"), &items); !errors.Is(err, context.Canceled) {
+		t.Errorf("FindContext: expected context.Canceled, got: %v", err)
+	}
+
+	if err := paginator.Find(db.Table("items").WithContext(canceled), &items); !errors.Is(err, context.Canceled) {
+		t.Errorf("Find: expected context.Canceled, got: %v", err)
+	}
+
+	// the client disconnects while the page query runs
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	mock.ExpectQuery(`SELECT * FROM (SELECT * FROM items) AS t ORDER BY "id" asc LIMIT 2`).
+		WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	if err := paginator.FindSQLContext(ctx, sqlDB, "SELECT * FROM items", nil, &items); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FindSQLContext: expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDefaultCursorPrimaryKey(t *testing.T) {
+	type Clap struct {
+		MaterialID uint `gorm:"primary_key"`
+		ClapperID  uint `gorm:"primary_key;column:user_id"`
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	paginator, err := New(Options{
+		Source:        FromValues(url.Values{}),
+		Model:         &Clap{},
+		Limit:         2,
+		Lookahead:     true,
+		CountStrategy: common.CountNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT * FROM (SELECT * FROM claps) AS t ORDER BY "material_id" asc, "user_id" asc LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"material_id", "user_id"}).AddRow(1, 1).AddRow(1, 2).AddRow(2, 1))
+
+	var claps []Clap
+	if err := paginator.FindSQL(db, "SELECT * FROM claps", nil, &claps); err != nil {
+		t.Fatal(err)
+	}
+
+	next := cursor.New(2).AddField("material_id", uint(1), common.DirectionAsc).AddField("user_id", uint(2), common.DirectionAsc).Encode()
+	if p := paginator.PageInfo; p == nil || p.Next != next || !p.HasNext {
+		t.Errorf("Wrong page info: %+v", p)
+	}
+}
+
+func TestFindLookaheadRange(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := cursor.New(2).AddField("id", 1, common.DirectionAsc).Encode()
+	before := cursor.New(2).AddField("id", 10, common.DirectionAsc).Encode()
+
+	paginator, err := New(Options{
+		Source:        FromValues(url.Values{"after": {after}, "before": {before}}),
+		Model:         &Item{},
+		DB:            db,
+		Limit:         2,
+		Lookahead:     true,
+		CountStrategy: common.CountNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT * FROM (SELECT * FROM "items") as t WHERE ("id" > $1) AND ("id" < $2) ORDER BY "id" asc,"id" desc LIMIT 3`).
+		WithArgs(1, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3).AddRow(4))
+
+	var items []Item
+	if err := paginator.Find(db.Table("items"), &items); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if len(items) != 2 || items[1].ID != 3 {
+		t.Errorf("Wrong items: %+v", items)
+	}
+
+	if p := paginator.PageInfo; p == nil || !p.RangeTruncated || !p.HasNext || !p.HasPrev {
+		t.Errorf("Wrong page info: %+v", p)
+	}
+}
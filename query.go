@@ -0,0 +1,54 @@
+package pagination
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Page is result of Query: items with page info
+type Page[T any] struct {
+	Items    []T       `json:"items"`
+	PageInfo *PageInfo `json:"pagination"`
+}
+
+// Query finds page of tx rows as T. T is the model of the paginator if Options.Model is nil:
+//
+//	page, err := pagination.Query[models.User](ctx, paginator, db.Where("role = ?", role))
+func Query[T any](ctx context.Context, p *Paginator, tx *gorm.DB) (Page[T], error) {
+	setModel[T](p)
+
+	var items []T
+	if err := p.FindContext(ctx, tx, &items); err != nil {
+		return Page[T]{}, err
+	}
+
+	return Page[T]{Items: items, PageInfo: p.PageInfo}, nil
+}
+
+// QuerySQL finds page of database/sql query rows as T, see Paginator.FindSQL
+func QuerySQL[T any](ctx context.Context, p *Paginator, db Querier, query string, args ...interface{}) (Page[T], error) {
+	setModel[T](p)
+
+	var items []T
+	if err := p.FindSQLContext(ctx, db, query, args, &items); err != nil {
+		return Page[T]{}, err
+	}
+
+	return Page[T]{Items: items, PageInfo: p.PageInfo}, nil
+}
+
+// setModel sets model of type T (pointers are dereferenced) if paginator has no model
+func setModel[T any](p *Paginator) {
+	if p.options.Model != nil {
+		return
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	p.options.Model = reflect.New(typ).Interface()
+}
@@ -0,0 +1,144 @@
+// Package relay maps GraphQL Relay connection arguments to the paginator and its result to Relay connection
+package relay
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	pagination "github.com/rosberry/go-pagination"
+	"github.com/rosberry/go-pagination/common"
+)
+
+type (
+	// Args of Relay connection field. Sorting is optional json sorting of the paginator
+	Args struct {
+		First   *int
+		After   *string
+		Last    *int
+		Before  *string
+		Sorting string
+	}
+
+	// Connection is Relay connection of T nodes.
+	// gqlgen binds models to aliases: type UserConnection = relay.Connection[models.User]
+	Connection[T any] struct {
+		Edges      []Edge[T] `json:"edges"`
+		PageInfo   PageInfo  `json:"pageInfo"`
+		TotalCount int       `json:"totalCount"`
+	}
+
+	// Edge is node with its cursor
+	Edge[T any] struct {
+		Cursor string `json:"cursor"`
+		Node   T      `json:"node"`
+	}
+
+	// PageInfo of Relay connection
+	PageInfo struct {
+		StartCursor     *string `json:"startCursor"`
+		EndCursor       *string `json:"endCursor"`
+		HasNextPage     bool    `json:"hasNextPage"`
+		HasPreviousPage bool    `json:"hasPreviousPage"`
+	}
+)
+
+// New returns paginator of Relay arguments, they replace o.Source.
+// first takes the start of the list or of the rows before the before cursor, last takes the end of the list
+// or of the rows after the after cursor. Range of after and before is read from after for first and from before for last
+func New(args Args, o pagination.Options) (*pagination.Paginator, error) {
+	source, err := args.source()
+	if err != nil {
+		return nil, err
+	}
+
+	o.Source = source
+	o.FromEnd = args.Last != nil
+	o.FromStart = args.First != nil
+
+	return pagination.New(o)
+}
+
+// Find finds connection of tx rows
+func Find[T any](ctx context.Context, p *pagination.Paginator, tx *gorm.DB) (*Connection[T], error) {
+	page, err := pagination.Query[T](ctx, p, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnection(p, page.Items)
+}
+
+// FindSQL finds connection of database/sql query rows
+func FindSQL[T any](ctx context.Context, p *pagination.Paginator, db pagination.Querier, query string, args ...interface{}) (*Connection[T], error) {
+	page, err := pagination.QuerySQL[T](ctx, p, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnection(p, page.Items)
+}
+
+// NewConnection makes connection of items found by p, every edge has cursor of its node.
+// It returns error of the cursors, e.g. if p has not decoded the request
+func NewConnection[T any](p *pagination.Paginator, items []T) (*Connection[T], error) {
+	cursors, err := p.Cursors(items)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Connection[T]{Edges: make([]Edge[T], 0, len(items))}
+	for i, item := range items {
+		conn.Edges = append(conn.Edges, Edge[T]{Cursor: cursors[i], Node: item})
+	}
+
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	if p.PageInfo != nil {
+		conn.PageInfo.HasNextPage = p.PageInfo.HasNext
+		conn.PageInfo.HasPreviousPage = p.PageInfo.HasPrev
+		conn.TotalCount = p.PageInfo.TotalRows
+	}
+
+	return conn, nil
+}
+
+// source maps args to request values: after/before cursors and limit of first or last
+func (a Args) source() (pagination.RequestSource, error) {
+	if a.First != nil && a.Last != nil {
+		return nil, common.ErrFirstAndLast
+	}
+
+	values := url.Values{}
+
+	for _, limit := range []*int{a.First, a.Last} {
+		if limit == nil {
+			continue
+		}
+
+		if *limit < 1 {
+			return nil, common.ErrInvalidFirstOrLast
+		}
+
+		values.Set(pagination.DefaultQueryParams.Limit, strconv.Itoa(*limit))
+	}
+
+	if a.After != nil {
+		values.Set(pagination.DefaultQueryParams.After, *a.After)
+	}
+
+	if a.Before != nil {
+		values.Set(pagination.DefaultQueryParams.Before, *a.Before)
+	}
+
+	if a.Sorting != "" {
+		values.Set(pagination.DefaultQueryParams.Sorting, a.Sorting)
+	}
+
+	return pagination.FromValues(values), nil
+}
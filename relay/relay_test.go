@@ -0,0 +1,135 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	pagination "github.com/rosberry/go-pagination"
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+func TestConnection(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	query := "SELECT * FROM items"
+	options := pagination.Options{Placeholder: cursor.Dollar, Lookahead: true, CountStrategy: common.CountNone}
+	ids := func(values ...int) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, v := range values {
+			rows.AddRow(v)
+		}
+
+		return rows
+	}
+	find := func(args Args) *Connection[Item] {
+		p, err := New(args, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := FindSQL[Item](context.Background(), p, db, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return conn
+	}
+	nodes := func(conn *Connection[Item]) (ids []uint) {
+		for _, e := range conn.Edges {
+			ids = append(ids, e.Node.ID)
+		}
+
+		return ids
+	}
+
+	two := 2
+
+	// last without before is the end of the list
+	mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t ORDER BY "id" desc LIMIT 3`).WillReturnRows(ids(5, 4, 3))
+
+	conn := find(Args{Last: &two})
+	last := conn
+	if got := nodes(conn); len(got) != 2 || got[0] != 4 || got[1] != 5 || !conn.PageInfo.HasPreviousPage || conn.PageInfo.HasNextPage {
+		t.Errorf("Wrong last page: %v %+v", got, conn.PageInfo)
+	}
+
+	if conn.PageInfo.StartCursor == nil || *conn.PageInfo.StartCursor != conn.Edges[0].Cursor || *conn.PageInfo.EndCursor != conn.Edges[1].Cursor {
+		t.Errorf("Wrong start/end cursors: %+v", conn.PageInfo)
+	}
+
+	// cursor of the first edge continues the list backward from its node
+	mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t WHERE (("id" < $1)) ORDER BY "id" desc LIMIT 3`).WithArgs(4).WillReturnRows(ids(3, 2))
+
+	conn = find(Args{Last: &two, Before: &conn.Edges[0].Cursor})
+	before := conn
+	if got := nodes(conn); len(got) != 2 || got[0] != 2 || got[1] != 3 || conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Errorf("Wrong before page: %v %+v", got, conn.PageInfo)
+	}
+
+	mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t WHERE (("id" > $1)) ORDER BY "id" asc LIMIT 3`).WithArgs(2).WillReturnRows(ids(3, 4, 5))
+
+	conn = find(Args{First: &two, After: &conn.Edges[0].Cursor})
+	if got := nodes(conn); len(got) != 2 || got[0] != 3 || got[1] != 4 || !conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Errorf("Wrong after page: %v %+v", got, conn.PageInfo)
+	}
+
+	// last with after is the end of the rows after the cursor
+	mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t WHERE (("id" > $1)) ORDER BY "id" desc LIMIT 3`).WithArgs(3).WillReturnRows(ids(5, 4))
+
+	conn = find(Args{Last: &two, After: &before.Edges[1].Cursor})
+	if got := nodes(conn); len(got) != 2 || got[0] != 4 || got[1] != 5 || !conn.PageInfo.HasPreviousPage || conn.PageInfo.HasNextPage {
+		t.Errorf("Wrong last after page: %v %+v", got, conn.PageInfo)
+	}
+
+	// last of the range is read backward from the before cursor
+	mock.ExpectQuery(`SELECT * FROM (`+query+`) AS t WHERE (("id" < $1)) AND (("id" > $2)) ORDER BY "id" desc LIMIT 3`).
+		WithArgs(5, 2).WillReturnRows(ids(4, 3))
+
+	conn = find(Args{Last: &two, After: &before.Edges[0].Cursor, Before: &last.Edges[1].Cursor})
+	if got := nodes(conn); len(got) != 2 || got[0] != 3 || got[1] != 4 || !conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Errorf("Wrong last range page: %v %+v", got, conn.PageInfo)
+	}
+
+	// first with before is the start of the rows before the cursor
+	mock.ExpectQuery(`SELECT * FROM (` + query + `) AS t WHERE (("id" < $1)) ORDER BY "id" asc LIMIT 3`).WithArgs(5).WillReturnRows(ids(1, 2, 3))
+
+	conn = find(Args{First: &two, Before: &last.Edges[1].Cursor})
+	if got := nodes(conn); len(got) != 2 || got[0] != 1 || got[1] != 2 || conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Errorf("Wrong first before page: %v %+v", got, conn.PageInfo)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// cursors of the edges need the decoded request
+	p, err := New(Args{First: &two}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewConnection(p, []Item{{ID: 1}}); !errors.Is(err, common.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got: %v", err)
+	}
+
+	zero := 0
+	if _, err := New(Args{First: &two, Last: &two}, options); !errors.Is(err, common.ErrFirstAndLast) {
+		t.Errorf("Expected ErrFirstAndLast, got: %v", err)
+	}
+
+	if _, err := New(Args{First: &zero}, options); !errors.Is(err, common.ErrInvalidFirstOrLast) {
+		t.Errorf("Expected ErrInvalidFirstOrLast, got: %v", err)
+	}
+}
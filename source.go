@@ -0,0 +1,206 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// RequestSource provides raw pagination values of the request
+	RequestSource interface {
+		Cursor() string
+		After() string
+		Before() string
+		Sorting() string
+		Limit() string
+	}
+
+	// PageSource is RequestSource with page number params of common.ModeOffset
+	PageSource interface {
+		RequestSource
+		Page() string
+		PerPage() string
+	}
+
+	// QueryParams are names of the query params with pagination values. Empty name means default name
+	QueryParams struct {
+		Cursor  string
+		After   string
+		Before  string
+		Sorting string
+		Limit   string
+		Page    string
+		PerPage string
+	}
+
+	// EchoContext is part of echo.Context used by FromEcho
+	EchoContext interface {
+		QueryParam(name string) string
+	}
+
+	funcSource struct {
+		get    func(name string) string
+		params QueryParams
+	}
+
+	ginSource struct {
+		c      *gin.Context
+		custom *RequestOptions
+		params QueryParams
+	}
+)
+
+// DefaultQueryParams are names of the query params used by default
+var DefaultQueryParams = QueryParams{
+	Cursor:  "cursor",
+	After:   "after",
+	Before:  "before",
+	Sorting: "sorting",
+	Limit:   "limit",
+	Page:    "page",
+	PerPage: "per_page",
+}
+
+// FromRequest returns source of *http.Request query params
+func FromRequest(r *http.Request) RequestSource {
+	return DefaultQueryParams.FromRequest(r)
+}
+
+// FromValues returns source of url.Values
+func FromValues(values url.Values) RequestSource {
+	return DefaultQueryParams.FromValues(values)
+}
+
+// FromEcho returns source of echo.Context query params
+func FromEcho(c EchoContext) RequestSource {
+	return DefaultQueryParams.FromEcho(c)
+}
+
+// FromFunc returns source that gets values by param names, e.g. for fiber:
+//
+//	pagination.FromFunc(func(name string) string { return c.Query(name) })
+func FromFunc(get func(name string) string) RequestSource {
+	return DefaultQueryParams.FromFunc(get)
+}
+
+// FromGin returns source of gin.Context query params, custom getters are used instead of query params if set
+func FromGin(c *gin.Context, custom *RequestOptions) RequestSource {
+	var params QueryParams
+	if custom != nil {
+		params = custom.Params
+	}
+
+	return &ginSource{c: c, custom: custom, params: params.withDefaults()}
+}
+
+// FromRequest returns source of *http.Request query params
+func (p QueryParams) FromRequest(r *http.Request) RequestSource {
+	return p.FromValues(r.URL.Query())
+}
+
+// FromValues returns source of url.Values
+func (p QueryParams) FromValues(values url.Values) RequestSource {
+	return p.FromFunc(values.Get)
+}
+
+// FromEcho returns source of echo.Context query params
+func (p QueryParams) FromEcho(c EchoContext) RequestSource {
+	return p.FromFunc(c.QueryParam)
+}
+
+// FromFunc returns source that gets values by param names
+func (p QueryParams) FromFunc(get func(name string) string) RequestSource {
+	return &funcSource{get: get, params: p.withDefaults()}
+}
+
+func (p QueryParams) withDefaults() QueryParams {
+	if p.Cursor == "" {
+		p.Cursor = DefaultQueryParams.Cursor
+	}
+	if p.After == "" {
+		p.After = DefaultQueryParams.After
+	}
+	if p.Before == "" {
+		p.Before = DefaultQueryParams.Before
+	}
+	if p.Sorting == "" {
+		p.Sorting = DefaultQueryParams.Sorting
+	}
+	if p.Limit == "" {
+		p.Limit = DefaultQueryParams.Limit
+	}
+	if p.Page == "" {
+		p.Page = DefaultQueryParams.Page
+	}
+	if p.PerPage == "" {
+		p.PerPage = DefaultQueryParams.PerPage
+	}
+
+	return p
+}
+
+func (s *funcSource) Cursor() string  { return s.get(s.params.Cursor) }
+func (s *funcSource) After() string   { return s.get(s.params.After) }
+func (s *funcSource) Before() string  { return s.get(s.params.Before) }
+func (s *funcSource) Sorting() string { return s.get(s.params.Sorting) }
+func (s *funcSource) Limit() string   { return s.get(s.params.Limit) }
+func (s *funcSource) Page() string    { return s.get(s.params.Page) }
+func (s *funcSource) PerPage() string { return s.get(s.params.PerPage) }
+
+func (s *ginSource) Cursor() string {
+	if s.custom != nil && s.custom.Cursor != nil {
+		return s.custom.Cursor(s.c)
+	}
+
+	return s.c.Query(s.params.Cursor)
+}
+
+func (s *ginSource) After() string {
+	if s.custom != nil && s.custom.After != nil {
+		return s.custom.After(s.c)
+	}
+
+	return s.c.Query(s.params.After)
+}
+
+func (s *ginSource) Before() string {
+	if s.custom != nil && s.custom.Before != nil {
+		return s.custom.Before(s.c)
+	}
+
+	return s.c.Query(s.params.Before)
+}
+
+func (s *ginSource) Sorting() string {
+	if s.custom != nil && s.custom.Sorting != nil {
+		return s.custom.Sorting(s.c)
+	}
+
+	return s.c.Query(s.params.Sorting)
+}
+
+func (s *ginSource) Limit() string {
+	if s.custom != nil && s.custom.Limit != nil {
+		return s.custom.Limit(s.c)
+	}
+
+	return s.c.Query(s.params.Limit)
+}
+
+func (s *ginSource) Page() string {
+	if s.custom != nil && s.custom.Page != nil {
+		return s.custom.Page(s.c)
+	}
+
+	return s.c.Query(s.params.Page)
+}
+
+func (s *ginSource) PerPage() string {
+	if s.custom != nil && s.custom.PerPage != nil {
+		return s.custom.PerPage(s.c)
+	}
+
+	return s.c.Query(s.params.PerPage)
+}
@@ -0,0 +1,253 @@
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/rosberry/go-pagination/common"
+	"github.com/rosberry/go-pagination/cursor"
+)
+
+type (
+	// Querier runs database/sql queries: *sql.DB, *sql.Tx, *sqlx.DB ...
+	Querier interface {
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	}
+
+	sqlExecutor struct {
+		ctx     context.Context
+		db      Querier
+		query   string
+		args    []interface{}
+		builder cursor.SQLBuilder
+	}
+
+	// row is *sql.Row for Querier without QueryRowContext
+	row struct {
+		rows *sql.Rows
+		err  error
+	}
+)
+
+// FindSQL finds page of the query rows with database/sql.
+// Query is wrapped as a subquery, its args must use the same placeholder style as Options.Placeholder.
+// Rows are scanned to dst (pointer to slice) by column names: `db` tag, gorm column name or snake case of the field name
+func (p *Paginator) FindSQL(db Querier, query string, args []interface{}, dst interface{}) error {
+	return p.FindSQLContext(context.Background(), db, query, args, dst)
+}
+
+// FindSQLContext is FindSQL with context of all paginator queries. It returns ctx.Err() if the context is done
+func (p *Paginator) FindSQLContext(ctx context.Context, db Querier, query string, args []interface{}, dst interface{}) error {
+	if p.options.Model == nil {
+		return common.ErrEmptyModelInPaginator
+	}
+
+	return p.find(ctx, &sqlExecutor{
+		ctx:     ctx,
+		db:      db,
+		query:   query,
+		args:    args,
+		builder: cursor.SQLBuilder{Placeholder: p.options.Placeholder, Quoter: p.options.Quoter},
+	}, dst)
+}
+
+func (e *sqlExecutor) find(dst interface{}, cursors ...*cursor.Cursor) error {
+	query, args := e.builder.Select(e.query, e.args, cursors...)
+
+	rows, err := e.db.QueryContext(e.ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanRows(rows, dst)
+}
+
+func (e *sqlExecutor) findPage(dst interface{}, c *cursor.Cursor, offset int) error {
+	query, args := e.builder.SelectOffset(e.query, e.args, offset, c)
+
+	rows, err := e.db.QueryContext(e.ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanRows(rows, dst)
+}
+
+func (e *sqlExecutor) count(cursors ...*cursor.Cursor) (count int64, err error) {
+	conditions := make([]*cursor.Cursor, 0, len(cursors))
+	for _, c := range cursors {
+		withoutLimit := *c
+		withoutLimit.Limit = 0
+
+		conditions = append(conditions, &withoutLimit)
+	}
+
+	query, args := e.builder.Select(e.query, e.args, conditions...)
+	err = e.queryRow("SELECT count(1) FROM ("+query+") AS c", args).Scan(&count)
+
+	return count, err
+}
+
+func (e *sqlExecutor) exists(c *cursor.Cursor) (bool, error) {
+	var count int64
+
+	query, args := e.builder.Select(e.query, e.args, c)
+	err := e.queryRow("SELECT count(1) FROM ("+query+") AS c", args).Scan(&count)
+
+	return count > 0, err
+}
+
+func (e *sqlExecutor) countLimit(limit int64) (count int64, err error) {
+	query, args := e.builder.Select(e.query, e.args, cursor.New(int(limit)))
+	err = e.queryRow("SELECT count(1) FROM ("+query+") AS c", args).Scan(&count)
+
+	return count, err
+}
+
+func (e *sqlExecutor) estimate() (int64, error) {
+	var plan []byte
+
+	query, args := e.builder.Select(e.query, e.args)
+	if err := e.queryRow("EXPLAIN (FORMAT JSON) "+query, args).Scan(&plan); err != nil {
+		return 0, err
+	}
+
+	return parseExplain(plan)
+}
+
+func (e *sqlExecutor) countQuery() CountQuery {
+	return CountQuery{SQL: e.query, Args: e.args}
+}
+
+func (e *sqlExecutor) context() context.Context {
+	return e.ctx
+}
+
+func (e *sqlExecutor) queryRow(query string, args []interface{}) *row {
+	rows, err := e.db.QueryContext(e.ctx, query, args...)
+
+	return &row{rows: rows, err: err}
+}
+
+func (r *row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+
+		return sql.ErrNoRows
+	}
+
+	return r.rows.Scan(dest...)
+}
+
+// scanRows appends rows to dst (pointer to slice of structs or scalars)
+func scanRows(rows *sql.Rows, dst interface{}) error {
+	slice := reflect.Indirect(reflect.ValueOf(dst))
+	elemType := slice.Type().Elem()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	// fields is nil for scalar elements, they are scanned from the first column
+	var fields map[string][]int
+	if isRowStruct(structType) {
+		fields = columnFields(structType, nil)
+	}
+
+	slice.Set(slice.Slice(0, 0))
+
+	for rows.Next() {
+		elem := reflect.New(structType).Elem()
+		values := make([]interface{}, len(columns))
+
+		for i, column := range columns {
+			switch {
+			case fields == nil && i == 0:
+				values[i] = elem.Addr().Interface()
+			case fields != nil && fields[column] != nil:
+				values[i] = elem.FieldByIndex(fields[column]).Addr().Interface()
+			default:
+				values[i] = new(sql.RawBytes)
+			}
+		}
+
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			elem = elem.Addr()
+		}
+
+		slice.Set(reflect.Append(slice, elem))
+	}
+
+	return rows.Err()
+}
+
+func isRowStruct(typ reflect.Type) bool {
+	scanner := reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+	return typ.Kind() == reflect.Struct && typ != reflect.TypeOf(time.Time{}) && !reflect.PtrTo(typ).Implements(scanner)
+}
+
+// columnFields maps column names to indexes of struct fields, embedded structs are included
+func columnFields(typ reflect.Type, index []int) map[string][]int {
+	fields := make(map[string][]int)
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for column, idx := range columnFields(f.Type, fieldIndex) {
+				if _, ok := fields[column]; !ok {
+					fields[column] = idx
+				}
+			}
+
+			continue
+		}
+
+		column := strings.Split(f.Tag.Get("db"), ",")[0]
+		if column == "-" {
+			continue
+		}
+
+		if column == "" {
+			column = (&schema.Schema{}).ParseField(f).DBName
+		}
+
+		if column == "" {
+			column = (&schema.NamingStrategy{}).ColumnName("", f.Name)
+		}
+
+		fields[column] = fieldIndex
+	}
+
+	return fields
+}